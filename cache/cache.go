@@ -0,0 +1,190 @@
+// Package cache stores expensive preprocessing (like building shortest
+// paths between valves) on local disk, so repeated runs and benchmarks on the
+// same input can skip it. It's opt-in, with -cache.
+//
+// Entries are keyed by a hash of the input and anything else the caller
+// says the result depends on, plus a hash of the running executable. Any
+// change to the code builds a different executable, so stale entries are
+// never read back (and are cleared out the next time something is written):
+// there's no version number to remember to bump.
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var (
+	enabled = flag.Bool("cache", false, "cache preprocessed input on disk between runs")
+	dir     = flag.String("cache-dir", "", "directory to cache preprocessed input in (default is the user cache directory)")
+)
+
+var (
+	executableHashOnce sync.Once
+	executableHash     []byte
+	executableHashErr  error
+)
+
+// The executable stands in for the code version, so it only needs hashing
+// once per run
+func hashExecutable() ([]byte, error) {
+	executableHashOnce.Do(func() {
+		path, err := os.Executable()
+		if err != nil {
+			executableHashErr = err
+			return
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			executableHashErr = err
+			return
+		}
+		defer file.Close()
+
+		hash := sha256.New()
+		if _, err := io.Copy(hash, file); err != nil {
+			executableHashErr = err
+			return
+		}
+		executableHash = hash.Sum(nil)
+	})
+	return executableHash, executableHashErr
+}
+
+func cacheDir() (string, error) {
+	if *dir != "" {
+		return *dir, nil
+	}
+
+	userDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(userDir, "aoc-2022"), nil
+}
+
+// Key hashes everything an entry depends on into a file name
+func Key(keys ...[]byte) string {
+	hash := sha256.New()
+	for _, key := range keys {
+		// prefix each key with its length, so that ("ab", "c") and
+		// ("a", "bc") don't collide
+		fmt.Fprintf(hash, "%d:", len(key))
+		hash.Write(key)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Load returns the cached result of compute for the given keys, computing
+// and storing it if it isn't cached yet. Without -cache, it just computes.
+//
+// T is stored with encoding/gob, so it can't contain cycles or unexported
+// fields: structures made of pointers need flattening first. Failing to read
+// or write the cache isn't fatal, it just means computing the value.
+func Load[T any](name string, compute func() (T, error), keys ...[]byte) (T, error) {
+	if !*enabled {
+		return compute()
+	}
+
+	path, err := entryPath(name, keys)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "not caching %s: %v\n", name, err)
+		return compute()
+	}
+
+	if value, err := read[T](path); err == nil {
+		return value, nil
+	}
+
+	value, err := compute()
+	if err != nil {
+		return value, err
+	}
+
+	if err := write(path, value); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to cache %s: %v\n", name, err)
+	}
+	prune(path)
+	return value, nil
+}
+
+func entryPath(name string, keys [][]byte) (string, error) {
+	executable, err := hashExecutable()
+	if err != nil {
+		return "", fmt.Errorf("failed to hash executable: %v", err)
+	}
+
+	dir, err := cacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find cache directory: %v", err)
+	}
+
+	// entries are grouped by executable, so old ones are easy to clear out
+	version := hex.EncodeToString(executable)[:16]
+	return filepath.Join(dir, name, version, Key(keys...)+".gob"), nil
+}
+
+// prune removes entries written by other versions of the code, as nothing
+// will read them again
+func prune(path string) {
+	versionDir := filepath.Dir(path)
+	nameDir := filepath.Dir(versionDir)
+
+	entries, err := os.ReadDir(nameDir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && entry.Name() != filepath.Base(versionDir) {
+			os.RemoveAll(filepath.Join(nameDir, entry.Name()))
+		}
+	}
+}
+
+func read[T any](path string) (T, error) {
+	var value T
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return value, err
+	}
+
+	err = gob.NewDecoder(bytes.NewReader(data)).Decode(&value)
+	return value, err
+}
+
+// writes to a temporary file first, so that a concurrent run never sees a
+// half-written entry
+func write[T any](path string, value T) error {
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(value); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(path), "entry")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(buffer.Bytes()); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), path)
+}
@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"testing"
+)
+
+func TestKeyIsUnambiguous(t *testing.T) {
+	if Key([]byte("ab"), []byte("c")) == Key([]byte("a"), []byte("bc")) {
+		t.Error("keys split in different places shouldn't collide")
+	}
+	if Key([]byte("a")) != Key([]byte("a")) {
+		t.Error("the same keys should give the same key")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	*enabled, *dir = true, t.TempDir()
+	defer func() { *enabled, *dir = false, "" }()
+
+	type value struct {
+		Names []string
+		Edges [][]int
+	}
+	computed := 0
+	compute := func() (value, error) {
+		computed++
+		return value{[]string{"AA", "BB"}, [][]int{{1}, {0}}}, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		v, err := Load("test", compute, []byte("input"))
+		if err != nil {
+			t.Fatalf("failed to load: %v", err)
+		}
+		if len(v.Names) != 2 || v.Edges[1][0] != 0 {
+			t.Errorf("loaded %+v", v)
+		}
+	}
+	if computed != 1 {
+		t.Errorf("computed %d times, expected once", computed)
+	}
+
+	if _, err := Load("test", compute, []byte("other input")); err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	if computed != 2 {
+		t.Errorf("computed %d times after changing the input, expected twice", computed)
+	}
+}
@@ -0,0 +1,28 @@
+// Package containers holds the generic data structures the days keep
+// needing, grown out of day 5's stack of crates.
+//
+// None of them panic on being empty, except where a method is documented to:
+// each has a Try variant which says whether there was anything there
+// instead. Every container can be iterated over with All, which gives a Seq.
+package containers
+
+// Seq is an iterator over values, calling yield with each in turn until it
+// returns false. It has the same shape as the standard library's iter.Seq, so
+// can be ranged over directly from Go 1.23.
+type Seq[T any] func(yield func(T) bool)
+
+// Collect gathers everything from an iterator into a slice
+func Collect[T any](seq Seq[T]) []T {
+	values := []T{}
+	seq(func(v T) bool {
+		values = append(values, v)
+		return true
+	})
+	return values
+}
+
+// the zero value of a type, to return alongside false from the Try methods
+func zero[T any]() T {
+	var z T
+	return z
+}
@@ -0,0 +1,147 @@
+package containers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStack(t *testing.T) {
+	var s Stack[int]
+	if _, ok := s.TryPop(); ok {
+		t.Error("popped from an empty stack")
+	}
+	if _, ok := s.TryPeek(); ok {
+		t.Error("peeked at an empty stack")
+	}
+
+	for i := 1; i <= 3; i++ {
+		s.Push(i)
+	}
+	if got := Collect(s.All()); !reflect.DeepEqual(got, []int{1, 2, 3}) {
+		t.Errorf("iterated %v, expected bottom to top", got)
+	}
+	if val, ok := s.TryPeek(); !ok || val != 3 {
+		t.Errorf("peeked %d, %v, expected 3", val, ok)
+	}
+	if val, ok := s.TryPop(); !ok || val != 3 || s.Size() != 2 {
+		t.Errorf("popped %d, %v, leaving %d", val, ok, s.Size())
+	}
+
+	s.TryPop()
+	s.TryPop()
+	if val, ok := s.TryPop(); ok || s.Size() != 0 {
+		t.Errorf("popped %d from a stack which should be empty", val)
+	}
+}
+
+func TestQueue(t *testing.T) {
+	var q Queue[string]
+	for _, v := range []string{"a", "b", "c"} {
+		q.Push(v)
+	}
+	if got := Collect(q.All()); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Errorf("iterated %v", got)
+	}
+
+	for _, expected := range []string{"a", "b", "c"} {
+		if val, ok := q.TryPop(); !ok || val != expected {
+			t.Errorf("popped %q, %v, expected %q", val, ok, expected)
+		}
+	}
+	if _, ok := q.TryPop(); ok || !q.Empty() {
+		t.Error("popped from an empty queue")
+	}
+}
+
+func TestDeque(t *testing.T) {
+	var d Deque[int]
+	// enough to make it grow while wrapped round
+	for i := 0; i < 20; i++ {
+		d.PushBack(i)
+		d.PushFront(-i - 1)
+	}
+
+	expected := []int{}
+	for i := -20; i < 20; i++ {
+		expected = append(expected, i)
+	}
+	if got := Collect(d.All()); !reflect.DeepEqual(got, expected) {
+		t.Errorf("iterated %v, expected %v", got, expected)
+	}
+	if d.At(0) != -20 || d.At(39) != 19 {
+		t.Errorf("at the ends were %d and %d", d.At(0), d.At(39))
+	}
+
+	if val, ok := d.TryPopFront(); !ok || val != -20 {
+		t.Errorf("popped %d, %v from the front", val, ok)
+	}
+	if val, ok := d.TryPopBack(); !ok || val != 19 {
+		t.Errorf("popped %d, %v from the back", val, ok)
+	}
+	if front, _ := d.TryFront(); front != -19 {
+		t.Errorf("front is %d", front)
+	}
+	if back, _ := d.TryBack(); back != 18 {
+		t.Errorf("back is %d", back)
+	}
+	if d.Len() != 38 {
+		t.Errorf("length is %d", d.Len())
+	}
+
+	var empty Deque[int]
+	if _, ok := empty.TryPopBack(); ok {
+		t.Error("popped from an empty deque")
+	}
+}
+
+func TestRingBuffer(t *testing.T) {
+	r := NewRingBuffer[rune](4)
+	dropped := []rune{}
+	for _, c := range "mjqjpqm" {
+		if old, ok := r.Push(c); ok {
+			dropped = append(dropped, old)
+		}
+	}
+
+	if got := string(Collect(r.All())); got != "jpqm" {
+		t.Errorf("holding %q, expected the last 4", got)
+	}
+	if string(dropped) != "mjq" {
+		t.Errorf("dropped %q", string(dropped))
+	}
+	if !r.Full() || r.Len() != 4 || r.Cap() != 4 || r.At(0) != 'j' {
+		t.Errorf("length %d of %d, oldest %c", r.Len(), r.Cap(), r.At(0))
+	}
+}
+
+func TestListMatchesMixing(t *testing.T) {
+	// day 20's example: moving each number by its value, in the original
+	// order
+	var l List[int]
+	elements := []*Element[int]{}
+	for _, v := range []int{1, 2, -3, 3, -2, 0, 4} {
+		elements = append(elements, l.PushBack(v))
+	}
+	for _, e := range elements {
+		l.Move(e, e.Value)
+	}
+
+	// the circle has no start, so read it from 0 like the puzzle does
+	got := []int{}
+	for e, i := elements[5], 0; i < l.Len(); e, i = e.Next(), i+1 {
+		got = append(got, e.Value)
+	}
+	if !reflect.DeepEqual(got, []int{0, 3, -2, 1, 2, -3, 4}) {
+		t.Errorf("mixed into %v", got)
+	}
+
+	if !l.Remove(elements[0]) || l.Remove(elements[0]) || l.Len() != 6 {
+		t.Error("expected to remove an element exactly once")
+	}
+	if got := Collect(l.All()); len(got) != 6 {
+		t.Errorf("iterated %v after removing", got)
+	}
+	if elements[5].Prev().Next() != elements[5] {
+		t.Error("links are inconsistent")
+	}
+}
@@ -0,0 +1,111 @@
+package containers
+
+// Deque is a double-ended queue, stored in a ring which doubles in size when
+// it fills up, so pushing and popping at either end is amortised O(1)
+type Deque[T any] struct {
+	values []T
+	// the index in values of the front
+	head int
+	size int
+}
+
+func (d *Deque[T]) Len() int {
+	return d.size
+}
+
+func (d *Deque[T]) Empty() bool {
+	return d.size == 0
+}
+
+// index gives where the i-th value from the front is stored
+func (d *Deque[T]) index(i int) int {
+	return (d.head + i) % len(d.values)
+}
+
+func (d *Deque[T]) grow() {
+	if d.size < len(d.values) {
+		return
+	}
+
+	capacity := 2 * len(d.values)
+	if capacity == 0 {
+		capacity = 8
+	}
+
+	values := make([]T, capacity)
+	for i := 0; i < d.size; i++ {
+		values[i] = d.values[d.index(i)]
+	}
+	d.values, d.head = values, 0
+}
+
+func (d *Deque[T]) PushBack(val T) {
+	d.grow()
+	d.values[(d.head+d.size)%len(d.values)] = val
+	d.size++
+}
+
+func (d *Deque[T]) PushFront(val T) {
+	d.grow()
+	d.head = (d.head + len(d.values) - 1) % len(d.values)
+	d.values[d.head] = val
+	d.size++
+}
+
+func (d *Deque[T]) TryPopFront() (T, bool) {
+	if d.size == 0 {
+		return zero[T](), false
+	}
+
+	val := d.values[d.head]
+	// clear the slot so it doesn't keep anything alive
+	d.values[d.head] = zero[T]()
+	d.head = d.index(1)
+	d.size--
+	return val, true
+}
+
+func (d *Deque[T]) TryPopBack() (T, bool) {
+	if d.size == 0 {
+		return zero[T](), false
+	}
+
+	last := d.index(d.size - 1)
+	val := d.values[last]
+	d.values[last] = zero[T]()
+	d.size--
+	return val, true
+}
+
+func (d *Deque[T]) TryFront() (T, bool) {
+	if d.size == 0 {
+		return zero[T](), false
+	}
+	return d.values[d.head], true
+}
+
+func (d *Deque[T]) TryBack() (T, bool) {
+	if d.size == 0 {
+		return zero[T](), false
+	}
+	return d.values[d.index(d.size-1)], true
+}
+
+// At returns the i-th value from the front, panicking if it's out of range
+func (d *Deque[T]) At(i int) T {
+	if i < 0 || i >= d.size {
+		panic("deque index out of range")
+	}
+	return d.values[d.index(i)]
+}
+
+// All iterates from the front to the back
+func (d *Deque[T]) All() Seq[T] {
+	return func(yield func(T) bool) {
+		for i := 0; i < d.size; i++ {
+			if !yield(d.values[d.index(i)]) {
+				return
+			}
+		}
+	}
+}
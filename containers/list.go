@@ -0,0 +1,128 @@
+package containers
+
+// List is a circular doubly-linked list: the element after the last is the
+// first. Elements stay put in memory as they're moved around the circle, so
+// they can be held onto as handles, like the numbers being mixed in day 20.
+type List[T any] struct {
+	front *Element[T]
+	size  int
+}
+
+type Element[T any] struct {
+	Value T
+	prev  *Element[T]
+	next  *Element[T]
+	list  *List[T]
+}
+
+// Next gives the element after this one, going round from the last to the
+// first
+func (e *Element[T]) Next() *Element[T] {
+	return e.next
+}
+
+// Prev gives the element before this one, going round from the first to the
+// last
+func (e *Element[T]) Prev() *Element[T] {
+	return e.prev
+}
+
+func (l *List[T]) Len() int {
+	return l.size
+}
+
+// Front gives the first element, or nil if the list is empty
+func (l *List[T]) Front() *Element[T] {
+	return l.front
+}
+
+// link puts e just after mark, or makes it the only element if there's no
+// mark
+func (l *List[T]) link(e *Element[T], mark *Element[T]) {
+	e.list = l
+	l.size++
+	if mark == nil {
+		e.prev, e.next = e, e
+		l.front = e
+		return
+	}
+
+	e.prev, e.next = mark, mark.next
+	mark.next.prev = e
+	mark.next = e
+}
+
+func (l *List[T]) unlink(e *Element[T]) {
+	if e.next == e {
+		l.front = nil
+	} else {
+		e.prev.next = e.next
+		e.next.prev = e.prev
+		if l.front == e {
+			l.front = e.next
+		}
+	}
+	l.size--
+}
+
+// PushBack adds a value at the end of the list, just before the front
+func (l *List[T]) PushBack(val T) *Element[T] {
+	e := &Element[T]{Value: val}
+	if l.front == nil {
+		l.link(e, nil)
+	} else {
+		l.link(e, l.front.prev)
+	}
+	return e
+}
+
+// Remove takes the element out of the list, returning whether it was in it
+func (l *List[T]) Remove(e *Element[T]) bool {
+	if e.list != l {
+		return false
+	}
+	l.unlink(e)
+	e.prev, e.next, e.list = nil, nil, nil
+	return true
+}
+
+// Move shifts an element n places around the circle: forwards if n is
+// positive, backwards if it's negative. As the element itself doesn't count
+// as a place, moving by a multiple of Len()-1 leaves the order unchanged.
+func (l *List[T]) Move(e *Element[T], n int) {
+	if e.list != l || l.size < 3 {
+		// with 2 or fewer elements, every order is the same circle
+		return
+	}
+
+	n %= l.size - 1
+	if n == 0 {
+		return
+	}
+
+	mark := e.prev
+	l.unlink(e)
+	if n > 0 {
+		for i := 0; i < n; i++ {
+			mark = mark.next
+		}
+	} else {
+		for i := 0; i > n; i-- {
+			mark = mark.prev
+		}
+	}
+	l.link(e, mark)
+}
+
+// All iterates once round the circle, starting from the front
+func (l *List[T]) All() Seq[T] {
+	return func(yield func(T) bool) {
+		e := l.front
+		for i := 0; i < l.size; i++ {
+			if !yield(e.Value) {
+				return
+			}
+			e = e.next
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package containers
+
+// Queue is a first-in, first-out queue
+type Queue[T any] struct {
+	values Deque[T]
+}
+
+func (q *Queue[T]) Len() int {
+	return q.values.Len()
+}
+
+func (q *Queue[T]) Empty() bool {
+	return q.values.Empty()
+}
+
+// Push adds a value to the back of the queue
+func (q *Queue[T]) Push(val T) {
+	q.values.PushBack(val)
+}
+
+// TryPop removes and returns the value at the front of the queue, or returns
+// false if it's empty
+func (q *Queue[T]) TryPop() (T, bool) {
+	return q.values.TryPopFront()
+}
+
+func (q *Queue[T]) TryPeek() (T, bool) {
+	return q.values.TryFront()
+}
+
+// All iterates from the front of the queue, which is next to be popped, to
+// the back
+func (q *Queue[T]) All() Seq[T] {
+	return q.values.All()
+}
@@ -0,0 +1,66 @@
+package containers
+
+// RingBuffer holds the most recent values pushed to it, up to a fixed
+// capacity, like a sliding window. Once it's full, each push drops the oldest
+// value.
+type RingBuffer[T any] struct {
+	values []T
+	// the index in values of the oldest
+	head int
+	size int
+}
+
+// NewRingBuffer makes a ring buffer holding up to capacity values, which must
+// be at least 1
+func NewRingBuffer[T any](capacity int) *RingBuffer[T] {
+	if capacity < 1 {
+		panic("ring buffer capacity must be at least 1")
+	}
+	return &RingBuffer[T]{values: make([]T, capacity)}
+}
+
+func (r *RingBuffer[T]) Len() int {
+	return r.size
+}
+
+func (r *RingBuffer[T]) Cap() int {
+	return len(r.values)
+}
+
+func (r *RingBuffer[T]) Full() bool {
+	return r.size == len(r.values)
+}
+
+// Push adds a value as the newest, returning the oldest value and true if it
+// had to be dropped to make room
+func (r *RingBuffer[T]) Push(val T) (T, bool) {
+	if r.size < len(r.values) {
+		r.values[(r.head+r.size)%len(r.values)] = val
+		r.size++
+		return zero[T](), false
+	}
+
+	dropped := r.values[r.head]
+	r.values[r.head] = val
+	r.head = (r.head + 1) % len(r.values)
+	return dropped, true
+}
+
+// At returns the i-th oldest value, panicking if it's out of range
+func (r *RingBuffer[T]) At(i int) T {
+	if i < 0 || i >= r.size {
+		panic("ring buffer index out of range")
+	}
+	return r.values[(r.head+i)%len(r.values)]
+}
+
+// All iterates from the oldest value to the newest
+func (r *RingBuffer[T]) All() Seq[T] {
+	return func(yield func(T) bool) {
+		for i := 0; i < r.size; i++ {
+			if !yield(r.At(i)) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package containers
+
+// Stack is a last-in, first-out stack, with the top at the end of the slice.
+// Unlike day 5's original, nothing panics on an empty stack: popping and
+// peeking say whether there was anything there.
+type Stack[T any] []T
+
+func (s *Stack[T]) Size() int {
+	return len(*s)
+}
+
+func (s *Stack[T]) Empty() bool {
+	return len(*s) == 0
+}
+
+func (s *Stack[T]) Push(val T) {
+	(*s) = append((*s), val)
+}
+
+// TryPop removes and returns the top value, or returns false if there isn't
+// one
+func (s *Stack[T]) TryPop() (T, bool) {
+	if len(*s) == 0 {
+		return zero[T](), false
+	}
+
+	final := len(*s) - 1
+	val := (*s)[final]
+	(*s) = (*s)[:final]
+
+	return val, true
+}
+
+// TryPeek returns the top value without removing it, or returns false if
+// there isn't one
+func (s *Stack[T]) TryPeek() (T, bool) {
+	if len(*s) == 0 {
+		return zero[T](), false
+	}
+	return (*s)[len(*s)-1], true
+}
+
+// All iterates from the bottom of the stack to the top
+func (s *Stack[T]) All() Seq[T] {
+	return func(yield func(T) bool) {
+		for _, val := range *s {
+			if !yield(val) {
+				return
+			}
+		}
+	}
+}
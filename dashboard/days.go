@@ -0,0 +1,117 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type Day struct {
+	// the directory holding the day's solution, relative to the module root
+	dir   string
+	title string
+	// simulated days accept -frames, and can be animated
+	simulated bool
+}
+
+// Registered days. Adding a day here is all that's needed for it to show up
+var days = []Day{
+	{"day1", "Calorie Counting", false},
+	{"day2", "Rock Paper Scissors", false},
+	{"day3", "Rucksack Reorganization", false},
+	{"day4", "Camp Cleanup", false},
+	{"day5", "Supply Stacks", false},
+	{"day6", "Tuning Trouble", false},
+	{"day7", "No Space Left On Device", false},
+	{"day8", "Treetop Tree House", false},
+	{"day9", "Rope Bridge", true},
+	{"day10", "Cathode-Ray Tube", false},
+	{"day11", "Monkey in the Middle", false},
+	{"day12", "Hill Climbing Algorithm", false},
+	{"day13", "Distress Signal", false},
+	{"day14", "Regolith Reservoir", true},
+	{"day15", "Beacon Exclusion Zone", false},
+	{"day16", "Proboscidea Volcanium", false},
+	{"day16_2", "Proboscidea Volcanium (rewrite)", false},
+	{"day17", "Pyroclastic Flow", true},
+	{"day18", "Boiling Boulders", false},
+	{"day19", "Not Enough Minerals", false},
+	{"day20", "Grove Positioning System", false},
+}
+
+type Input struct {
+	name string
+	// path to the input file, empty if the day doesn't need one
+	path string
+	// path to a parameter file to go with the input, if there is one
+	config string
+}
+
+// Finds the inputs for a day: any .txt file in its directory, and in the
+// day's directory under extra if it's a directory, like inputs/day1/input.txt.
+// If extra is a file instead, it's offered for every day. An input called
+// example.txt picks up example.json or example.toml alongside it as its
+// parameters, which is how the worked examples get their constants.
+func findInputs(root string, extra string, d Day) ([]Input, error) {
+	inputs, err := inputsIn(filepath.Join(root, d.dir), "")
+	if err != nil {
+		return nil, err
+	}
+
+	if extra != "" {
+		info, err := os.Stat(extra)
+		if err != nil {
+			return nil, err
+		}
+
+		if info.IsDir() {
+			dir := filepath.Join(extra, d.dir)
+			more, err := inputsIn(dir, dir)
+			if err != nil && !os.IsNotExist(err) {
+				return nil, err
+			}
+			inputs = append(inputs, more...)
+		} else {
+			inputs = append(inputs, withConfig(Input{name: extra, path: extra}))
+		}
+	}
+
+	// some days (like day 17) have their input built in
+	if len(inputs) == 0 {
+		inputs = append(inputs, Input{name: "(no input file)"})
+	}
+	return inputs, nil
+}
+
+// inputsIn lists the .txt files in a directory, sorted by name, with their
+// names prefixed so that inputs from different places can be told apart
+func inputsIn(dir string, prefix string) ([]Input, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := []Input{}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".txt" {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		inputs = append(inputs, withConfig(Input{name: filepath.Join(prefix, entry.Name()), path: path}))
+	}
+
+	sort.Slice(inputs, func(i, j int) bool { return inputs[i].name < inputs[j].name })
+	return inputs, nil
+}
+
+func withConfig(input Input) Input {
+	base := strings.TrimSuffix(input.path, filepath.Ext(input.path))
+	for _, ext := range []string{".json", ".toml"} {
+		if _, err := os.Stat(base + ext); err == nil {
+			input.config = base + ext
+			break
+		}
+	}
+	return input
+}
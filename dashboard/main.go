@@ -0,0 +1,502 @@
+// The dashboard is an interactive terminal app for exploring the solutions:
+// pick a day and an input, then run either part to see its answer and how
+// long it took, or watch one of the simulation days play out step by step.
+//
+// Run it from anywhere inside the module with `go run ./dashboard`. Each day
+// is built with `go build` the first time it's run, so this needs the Go
+// toolchain and the module's source, not just the dashboard's binary.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/WJBarnes456/aoc-2022/frames"
+)
+
+var inputsPath = flag.String("inputs", "", "input file to offer for every day, or directory with a subdirectory of inputs for each day, like inputs/day1/input.txt")
+
+type Screen int
+
+const (
+	DaysScreen Screen = iota
+	InputsScreen
+	ActionsScreen
+	PlayerScreen
+)
+
+// frames per second the player can run at
+var speeds = []float64{1, 2, 5, 10, 20, 50, 100, 200, 500, 1000}
+
+const TICK = 20 * time.Millisecond
+
+type Player struct {
+	process *Process
+	items   chan frames.Item
+	// closed once readFrames has stopped reading the output
+	read     chan struct{}
+	frame    *frames.Frame
+	count    int
+	paused   bool
+	speed    int
+	owed     float64
+	steps    int
+	finished bool
+	output   []string
+}
+
+type App struct {
+	runner *Runner
+	term   *Terminal
+	root   string
+
+	screen Screen
+	cursor int
+	day    Day
+	inputs []Input
+	input  Input
+
+	// the run in progress on the actions screen, if any
+	running     *Process
+	runningPart int
+	runStart    time.Time
+	cancelled   bool
+	done        chan Result
+	results     map[int]Result
+
+	player *Player
+	status string
+
+	// ctrl-c and kill, which quit the same way q does, so the terminal is
+	// restored and the builds are cleaned up
+	signals chan os.Signal
+}
+
+func findRoot() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("not inside the module")
+		}
+		dir = parent
+	}
+}
+
+func (a *App) actions() []string {
+	actions := []string{"Run part 1", "Run part 2"}
+	if a.day.simulated {
+		actions = append(actions, "Animate")
+	}
+	return actions
+}
+
+func (a *App) menuLength() int {
+	switch a.screen {
+	case DaysScreen:
+		return len(days)
+	case InputsScreen:
+		return len(a.inputs)
+	case ActionsScreen:
+		return len(a.actions())
+	default:
+		return 0
+	}
+}
+
+func menu(items []string, cursor int) []string {
+	lines := make([]string, 0, len(items))
+	for i, item := range items {
+		if i == cursor {
+			lines = append(lines, reverseVideo+"> "+item+resetAttributes)
+		} else {
+			lines = append(lines, "  "+item)
+		}
+	}
+	return lines
+}
+
+func (a *App) render() []string {
+	lines := []string{bold + "Advent of Code 2022" + resetAttributes, ""}
+
+	switch a.screen {
+	case DaysScreen:
+		lines = append(lines, "Pick a day:", "")
+		items := make([]string, 0, len(days))
+		for _, d := range days {
+			item := fmt.Sprintf("%-8s %s", d.dir, d.title)
+			if d.simulated {
+				item += dim + " (animated)" + resetAttributes
+			}
+			items = append(items, item)
+		}
+		lines = append(lines, menu(items, a.cursor)...)
+		lines = append(lines, "", dim+"up/down: move  enter: select  q: quit"+resetAttributes)
+
+	case InputsScreen:
+		lines = append(lines, fmt.Sprintf("%s: %s - pick an input:", a.day.dir, a.day.title), "")
+		items := make([]string, 0, len(a.inputs))
+		for _, input := range a.inputs {
+			item := input.name
+			if input.config != "" {
+				item += dim + " with " + filepath.Base(input.config) + resetAttributes
+			}
+			items = append(items, item)
+		}
+		lines = append(lines, menu(items, a.cursor)...)
+		lines = append(lines, "", dim+"up/down: move  enter: select  esc: back"+resetAttributes)
+
+	case ActionsScreen:
+		lines = append(lines, fmt.Sprintf("%s: %s on %s", a.day.dir, a.day.title, a.input.name), "")
+		lines = append(lines, menu(a.actions(), a.cursor)...)
+		lines = append(lines, "")
+
+		if a.running != nil {
+			lines = append(lines, fmt.Sprintf("Running part %d... %.1fs", a.runningPart, time.Since(a.runStart).Seconds()))
+		}
+		for part := 1; part <= 2; part++ {
+			result, exists := a.results[part]
+			switch {
+			case !exists:
+				continue
+			case result.err != nil:
+				lines = append(lines, fmt.Sprintf("Part %d failed: %v", part, result.err))
+			default:
+				answerLines := strings.Split(result.answer, "\n")
+				lines = append(lines, fmt.Sprintf("Part %d: %s%s%s (%v)", part, bold, answerLines[0], resetAttributes, result.elapsed.Round(time.Microsecond)))
+				lines = append(lines, answerLines[1:]...)
+			}
+		}
+		lines = append(lines, "", dim+"up/down: move  enter: run  esc: cancel/back"+resetAttributes)
+
+	case PlayerScreen:
+		lines = append(lines, a.player.render()...)
+	}
+
+	if a.status != "" {
+		lines = append(lines, "", a.status)
+	}
+	return lines
+}
+
+func (p *Player) render() []string {
+	state := "playing"
+	switch {
+	case p.finished:
+		state = "finished"
+	case p.paused:
+		state = "paused"
+	}
+
+	// the frame goes last, as it's the part that can be cut off if the
+	// terminal is too small
+	lines := []string{
+		fmt.Sprintf("frame %d, %s at %v fps", p.count, state, speeds[p.speed]),
+		dim + "space: pause  n/right: step  +/-: speed  esc: back" + resetAttributes,
+	}
+	lines = append(lines, p.output...)
+	lines = append(lines, "")
+	if p.frame != nil {
+		lines = append(lines, bold+p.frame.Title+resetAttributes)
+		lines = append(lines, p.frame.Lines...)
+	} else {
+		lines = append(lines, "waiting for the first frame...")
+	}
+	return lines
+}
+
+func (p *Player) readFrames() {
+	defer close(p.read)
+	for {
+		item, err := p.process.Items.Next()
+		if err != nil {
+			close(p.items)
+			return
+		}
+		p.items <- item
+	}
+}
+
+// wantsFrame says whether the player is ready to show another frame
+func (p *Player) wantsFrame() bool {
+	if p.finished {
+		return false
+	}
+	if p.paused {
+		return p.steps > 0
+	}
+	return p.owed >= 1
+}
+
+func (p *Player) receive(item frames.Item, ok bool) {
+	if !ok {
+		p.finished = true
+		return
+	}
+
+	// answers are printed between frames
+	if item.Frame == nil {
+		if answerLine.MatchString(item.Line) {
+			p.output = append(p.output, item.Line)
+		}
+		return
+	}
+
+	p.frame = item.Frame
+	p.count++
+	if p.paused {
+		p.steps--
+	} else {
+		p.owed--
+	}
+}
+
+func (a *App) startPlayer() {
+	process, err := a.runner.Start(a.day, a.input, "-frames")
+	if err != nil {
+		a.status = fmt.Sprintf("failed to start: %v", err)
+		return
+	}
+
+	a.player = &Player{process: process, items: make(chan frames.Item), read: make(chan struct{}), speed: 3}
+	go a.player.readFrames()
+	a.screen = PlayerScreen
+}
+
+func (a *App) stopPlayer() {
+	a.player.process.Kill()
+	// drain anything left so the reader finishes
+	go func(items <-chan frames.Item) {
+		for range items {
+		}
+	}(a.player.items)
+	// waiting closes the output, which mustn't happen while it's being read
+	<-a.player.read
+	a.player.process.Stop()
+	a.player = nil
+	a.screen = ActionsScreen
+}
+
+func (a *App) startRun(part int) {
+	process, err := a.runner.Start(a.day, a.input)
+	if err != nil {
+		a.results[part] = Result{part: part, err: err}
+		return
+	}
+
+	a.running, a.runningPart, a.runStart, a.cancelled = process, part, time.Now(), false
+	delete(a.results, part)
+	go func() {
+		result := process.Answer(part)
+		process.Stop()
+		a.done <- result
+	}()
+}
+
+// handleKey returns false once the app should quit
+func (a *App) handleKey(k KeyPress) bool {
+	a.status = ""
+
+	if a.screen == PlayerScreen {
+		p := a.player
+		switch {
+		case k.key == KeyEscape || (k.key == KeyRune && k.char == 'q'):
+			a.stopPlayer()
+		case k.key == KeyRune && k.char == ' ':
+			p.paused = !p.paused
+			p.owed, p.steps = 0, 0
+		case k.key == KeyRight || (k.key == KeyRune && k.char == 'n'):
+			p.paused = true
+			p.steps++
+		case k.key == KeyRune && (k.char == '+' || k.char == '='):
+			if p.speed < len(speeds)-1 {
+				p.speed++
+			}
+		case k.key == KeyRune && k.char == '-':
+			if p.speed > 0 {
+				p.speed--
+			}
+		}
+		return true
+	}
+
+	switch {
+	case k.key == KeyUp || (k.key == KeyRune && k.char == 'k'):
+		if a.cursor > 0 {
+			a.cursor--
+		}
+	case k.key == KeyDown || (k.key == KeyRune && k.char == 'j'):
+		if a.cursor < a.menuLength()-1 {
+			a.cursor++
+		}
+	case k.key == KeyRune && k.char == 'q' && a.screen == DaysScreen:
+		return false
+	case k.key == KeyEscape || k.key == KeyBackspace || (k.key == KeyRune && k.char == 'q'):
+		a.back()
+	case k.key == KeyEnter:
+		a.selectItem()
+	}
+	return true
+}
+
+func (a *App) back() {
+	switch a.screen {
+	case InputsScreen:
+		a.screen, a.cursor = DaysScreen, 0
+	case ActionsScreen:
+		if a.running != nil {
+			a.cancelled = true
+			a.running.Kill()
+			return
+		}
+		a.screen, a.cursor = InputsScreen, 0
+	}
+}
+
+func (a *App) selectItem() {
+	switch a.screen {
+	case DaysScreen:
+		a.day = days[a.cursor]
+		inputs, err := findInputs(a.root, *inputsPath, a.day)
+		if err != nil {
+			a.status = fmt.Sprintf("failed to find inputs: %v", err)
+			return
+		}
+		a.inputs = inputs
+		a.screen, a.cursor = InputsScreen, 0
+	case InputsScreen:
+		a.input = a.inputs[a.cursor]
+		a.results = map[int]Result{}
+		a.screen, a.cursor = ActionsScreen, 0
+	case ActionsScreen:
+		if a.running != nil {
+			a.status = "wait for the current run to finish, or press esc to cancel it"
+			return
+		}
+		if a.cursor == 2 {
+			a.status = "building " + a.day.dir + "..."
+			a.term.Draw(a.render())
+			a.status = ""
+			a.startPlayer()
+			return
+		}
+		a.startRun(a.cursor + 1)
+	}
+}
+
+func (a *App) loop() {
+	keys := make(chan KeyPress)
+	go ReadKeys(keys)
+
+	ticker := time.NewTicker(TICK)
+	defer ticker.Stop()
+
+	a.term.Draw(a.render())
+	for {
+		// only listen for frames when the player is ready for one, which
+		// leaves the simulation blocked on its output while paused
+		var items chan frames.Item
+		if a.player != nil && a.player.wantsFrame() {
+			items = a.player.items
+		}
+
+		select {
+		case <-a.signals:
+			return
+		case k, ok := <-keys:
+			if !ok || !a.handleKey(k) {
+				return
+			}
+			a.term.Resize()
+			a.term.Draw(a.render())
+		case item, ok := <-items:
+			a.player.receive(item, ok)
+			if a.player.paused || a.player.finished {
+				a.term.Draw(a.render())
+			}
+		case result := <-a.done:
+			if a.cancelled {
+				a.status = fmt.Sprintf("cancelled part %d", result.part)
+			} else {
+				a.results[result.part] = result
+			}
+			a.running = nil
+			a.term.Draw(a.render())
+		case <-ticker.C:
+			if a.player != nil && !a.player.paused {
+				// cap what's owed so a slow simulation doesn't build up a
+				// burst of frames to skip through later
+				fps := speeds[a.player.speed]
+				a.player.owed += fps * TICK.Seconds()
+				if a.player.owed > fps {
+					a.player.owed = fps
+				}
+				a.term.Draw(a.render())
+			} else if a.running != nil {
+				a.term.Draw(a.render())
+			}
+		}
+	}
+}
+
+func run() error {
+	flag.Parse()
+
+	// caught before anything needs cleaning up, so a signal arriving during
+	// setup, or while a day is building, still quits the loop cleanly
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	root, err := findRoot()
+	if err != nil {
+		return fmt.Errorf("failed to find module root: %v", err)
+	}
+	if *inputsPath != "" {
+		if _, err := os.Stat(*inputsPath); err != nil {
+			return fmt.Errorf("failed to find inputs: %v", err)
+		}
+	}
+
+	runner, err := NewRunner(root)
+	if err != nil {
+		return err
+	}
+	defer runner.Close()
+
+	term, err := OpenTerminal()
+	if err != nil {
+		return err
+	}
+	defer term.Close()
+
+	app := &App{runner: runner, term: term, root: root, done: make(chan Result), results: map[int]Result{}, signals: signals}
+	app.loop()
+
+	if app.player != nil {
+		app.stopPlayer()
+	}
+	if app.running != nil {
+		app.running.Kill()
+	}
+	return nil
+}
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to run dashboard:", err)
+		os.Exit(1)
+	}
+}
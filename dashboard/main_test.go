@@ -0,0 +1,105 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeKeys(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		reads    []string
+		expected []KeyPress
+	}{
+		{"letters", []string{"ab"}, []KeyPress{{KeyRune, 'a'}, {KeyRune, 'b'}}},
+		{"arrows", []string{"\x1b[A\x1b[B\x1bOC\x1b[D"}, []KeyPress{{key: KeyUp}, {key: KeyDown}, {key: KeyRight}, {key: KeyLeft}}},
+		{"enter and backspace", []string{"\r\n\x7f\b"}, []KeyPress{{key: KeyEnter}, {key: KeyEnter}, {key: KeyBackspace}, {key: KeyBackspace}}},
+		{"escape on its own", []string{"\x1b"}, []KeyPress{{key: KeyEscape}}},
+		{"escape then a letter", []string{"\x1bq"}, []KeyPress{{key: KeyEscape}, {KeyRune, 'q'}}},
+		{"arrow split after [", []string{"x\x1b[", "Cy"}, []KeyPress{{KeyRune, 'x'}, {key: KeyRight}, {KeyRune, 'y'}}},
+		{"unknown sequence", []string{"\x1b[Hz"}, []KeyPress{{KeyRune, 'z'}}},
+	} {
+		var d keyDecoder
+		got := []KeyPress{}
+		for _, read := range c.reads {
+			got = append(got, d.decodeKeys([]byte(read))...)
+		}
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("%s: decoded %v, expected %v", c.name, got, c.expected)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	for _, c := range []struct {
+		line     string
+		cols     int
+		expected string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 3, "hel"},
+		{"hello", 0, ""},
+		// escape codes don't take up columns, and are kept even past the cut
+		{bold + "hello" + resetAttributes, 2, bold + "he" + resetAttributes},
+		{"héllo", 2, "hé"},
+	} {
+		if got := truncate(c.line, c.cols); got != c.expected {
+			t.Errorf("truncating %q to %d gave %q, expected %q", c.line, c.cols, got, c.expected)
+		}
+	}
+}
+
+func TestFindInputs(t *testing.T) {
+	root, extra := t.TempDir(), t.TempDir()
+	for _, path := range []string{
+		"day1/example.txt",
+		"day1/example.json",
+		"day1/notes.md",
+		"day3/notes.md",
+		"inputs/day1/input.txt",
+		"inputs/day2/input.txt",
+	} {
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+	single := filepath.Join(extra, "mine.txt")
+	if err := os.WriteFile(single, nil, 0o644); err != nil {
+		t.Fatalf("failed to write %s: %v", single, err)
+	}
+
+	day1 := Day{"day1", "Calorie Counting", false}
+	inputsDir := filepath.Join(root, "inputs")
+	for _, c := range []struct {
+		name     string
+		extra    string
+		day      Day
+		expected []Input
+	}{
+		{"day's own directory", "", day1, []Input{
+			{"example.txt", filepath.Join(root, "day1/example.txt"), filepath.Join(root, "day1/example.json")},
+		}},
+		{"directory of inputs", inputsDir, day1, []Input{
+			{"example.txt", filepath.Join(root, "day1/example.txt"), filepath.Join(root, "day1/example.json")},
+			{filepath.Join(inputsDir, "day1/input.txt"), filepath.Join(inputsDir, "day1/input.txt"), ""},
+		}},
+		{"single input", single, Day{"day3", "Rucksack Reorganization", false}, []Input{
+			{single, single, ""},
+		}},
+	} {
+		got, err := findInputs(root, c.extra, c.day)
+		if err != nil {
+			t.Errorf("%s: failed to find inputs: %v", c.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("%s: found %v, expected %v", c.name, got, c.expected)
+		}
+	}
+}
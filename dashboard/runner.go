@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/WJBarnes456/aoc-2022/frames"
+)
+
+// Days are separate binaries, so rather than calling the solvers directly,
+// the dashboard builds each day once and runs it on the chosen input.
+type Runner struct {
+	root   string
+	binDir string
+	built  map[string]string
+}
+
+func NewRunner(root string) (*Runner, error) {
+	binDir, err := os.MkdirTemp("", "aoc-dashboard")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create build directory: %v", err)
+	}
+	return &Runner{root, binDir, map[string]string{}}, nil
+}
+
+func (r *Runner) Close() error {
+	return os.RemoveAll(r.binDir)
+}
+
+func (r *Runner) binary(d Day) (string, error) {
+	if path, built := r.built[d.dir]; built {
+		return path, nil
+	}
+
+	path := filepath.Join(r.binDir, d.dir)
+	cmd := exec.Command("go", "build", "-o", path, "./"+d.dir)
+	cmd.Dir = r.root
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to build %s: %v\n%s", d.dir, err, output)
+	}
+
+	r.built[d.dir] = path
+	return path, nil
+}
+
+type Process struct {
+	name    string
+	cmd     *exec.Cmd
+	stdin   *os.File
+	workDir string
+	stderr  bytes.Buffer
+	Items   *frames.Reader
+}
+
+// Start runs a day on an input. Days either read stdin or input.txt in the
+// working directory, so the input is provided both ways.
+func (r *Runner) Start(d Day, input Input, args ...string) (*Process, error) {
+	path, err := r.binary(d)
+	if err != nil {
+		return nil, err
+	}
+
+	workDir, err := os.MkdirTemp(r.binDir, "run")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create working directory: %v", err)
+	}
+
+	// the day runs in its own directory, so relative paths won't work
+	if input.config != "" {
+		config, err := filepath.Abs(input.config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find config: %v", err)
+		}
+		args = append([]string{"-config", config}, args...)
+	}
+
+	p := &Process{name: d.dir, cmd: exec.Command(path, args...), workDir: workDir}
+	p.cmd.Dir = workDir
+	p.cmd.Stderr = &p.stderr
+
+	if input.path != "" {
+		data, err := os.ReadFile(input.path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read input: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(workDir, "input.txt"), data, 0o644); err != nil {
+			return nil, fmt.Errorf("failed to copy input: %v", err)
+		}
+
+		p.stdin, err = os.Open(input.path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open input: %v", err)
+		}
+		p.cmd.Stdin = p.stdin
+	}
+
+	stdout, err := p.cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stdout: %v", err)
+	}
+	p.Items = frames.NewReader(stdout)
+
+	if err := p.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %v", d.dir, err)
+	}
+	return p, nil
+}
+
+// Stop kills the process if it's still running and cleans up after it. Like
+// Wait, it closes the output, so nothing can still be reading from Items.
+func (p *Process) Stop() error {
+	if p.cmd.ProcessState == nil {
+		p.cmd.Process.Kill()
+	}
+	err := p.Wait()
+	if p.stdin != nil {
+		p.stdin.Close()
+	}
+	os.RemoveAll(p.workDir)
+	return err
+}
+
+func (p *Process) Wait() error {
+	if p.cmd.ProcessState != nil {
+		return nil
+	}
+	if err := p.cmd.Wait(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(p.stderr.String()))
+	}
+	return nil
+}
+
+// The days aren't entirely consistent in how they print their answers,
+// e.g. "Part 1: 24000", "part 1: 33" and "Part 1 13140"
+var answerLine = regexp.MustCompile(`(?i)^part ?(\d)\b:?\s*(.*)$`)
+
+type Result struct {
+	part    int
+	answer  string
+	elapsed time.Duration
+	err     error
+}
+
+// Answer reads the process's output until it prints the answer to the given
+// part. Each day solves its parts in order, so part 2's time is measured
+// from when part 1's answer appeared rather than from the start.
+func (p *Process) Answer(part int) Result {
+	partStart := time.Now()
+	for {
+		item, err := p.Items.Next()
+		if err == io.EOF {
+			if err := p.Wait(); err != nil {
+				return Result{part: part, err: err}
+			}
+			return Result{part: part, err: fmt.Errorf("%s finished without printing part %d", p.name, part)}
+		}
+		if err != nil {
+			return Result{part: part, err: err}
+		}
+
+		match := answerLine.FindStringSubmatch(item.Line)
+		if match == nil {
+			continue
+		}
+
+		if match[1] != fmt.Sprint(part) {
+			partStart = time.Now()
+			continue
+		}
+
+		result := Result{part: part, answer: match[2], elapsed: time.Since(partStart)}
+
+		// day 10 draws its answer on the lines after the heading
+		if result.answer == "" {
+			lines := []string{}
+			for item, err := p.Items.Next(); err == nil; item, err = p.Items.Next() {
+				lines = append(lines, item.Line)
+			}
+			result.answer = strings.Join(lines, "\n")
+		}
+		return result
+	}
+}
+
+// Kill stops the process early. Unlike Stop, it's safe to call while
+// another goroutine is reading the output.
+func (p *Process) Kill() {
+	p.cmd.Process.Kill()
+}
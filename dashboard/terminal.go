@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Raw ANSI escape codes, rather than pulling in a terminal library
+const (
+	clearScreen     = "\x1b[H\x1b[2J"
+	altScreenOn     = "\x1b[?1049h"
+	altScreenOff    = "\x1b[?1049l"
+	cursorOff       = "\x1b[?25l"
+	cursorOn        = "\x1b[?25h"
+	reverseVideo    = "\x1b[7m"
+	bold            = "\x1b[1m"
+	dim             = "\x1b[2m"
+	resetAttributes = "\x1b[0m"
+)
+
+type Key int
+
+const (
+	KeyRune Key = iota
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyEnter
+	KeyEscape
+	KeyBackspace
+)
+
+type KeyPress struct {
+	key  Key
+	char rune
+}
+
+type Terminal struct {
+	out      *bufio.Writer
+	oldState string
+	rows     int
+	cols     int
+}
+
+// stty is the least painful way of getting the terminal out of line mode
+// without syscalls that differ between platforms
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+func OpenTerminal() (*Terminal, error) {
+	oldState, err := stty("-g")
+	if err != nil {
+		return nil, fmt.Errorf("stdin is not a terminal: %v", err)
+	}
+
+	// cbreak rather than fully raw, so that ctrl-c still sends SIGINT, which
+	// the app catches to put the terminal back before quitting
+	if _, err := stty("-icanon", "-echo", "min", "1"); err != nil {
+		return nil, fmt.Errorf("failed to set terminal mode: %v", err)
+	}
+
+	t := &Terminal{out: bufio.NewWriter(os.Stdout), oldState: oldState}
+	t.Resize()
+	t.out.WriteString(altScreenOn + cursorOff)
+	t.out.Flush()
+	return t, nil
+}
+
+func (t *Terminal) Close() {
+	t.out.WriteString(resetAttributes + cursorOn + altScreenOff)
+	t.out.Flush()
+	stty(t.oldState)
+}
+
+// Resize picks up the size of the terminal, falling back to 80x24
+func (t *Terminal) Resize() {
+	t.rows, t.cols = 24, 80
+	size, err := stty("size")
+	if err != nil {
+		return
+	}
+
+	var rows, cols int
+	if _, err := fmt.Sscanf(size, "%d %d", &rows, &cols); err == nil && rows > 0 && cols > 0 {
+		t.rows, t.cols = rows, cols
+	}
+}
+
+// Draw replaces the screen with the given lines, cut down to fit
+func (t *Terminal) Draw(lines []string) {
+	t.out.WriteString(clearScreen)
+	for i, line := range lines {
+		if i >= t.rows {
+			break
+		}
+		if i > 0 {
+			t.out.WriteString("\r\n")
+		}
+		t.out.WriteString(truncate(line, t.cols))
+	}
+	t.out.Flush()
+}
+
+// Cuts a line down to a number of visible columns, skipping over escape
+// codes so that they don't count towards the width
+func truncate(line string, cols int) string {
+	var b strings.Builder
+	visible := 0
+	inEscape := false
+	for _, r := range line {
+		switch {
+		case inEscape:
+			b.WriteRune(r)
+			if ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') {
+				inEscape = false
+			}
+		case r == '\x1b':
+			b.WriteRune(r)
+			inEscape = true
+		case visible < cols:
+			b.WriteRune(r)
+			visible++
+		}
+	}
+	return b.String()
+}
+
+// ReadKeys decodes key presses from stdin until it's closed
+func ReadKeys(keys chan<- KeyPress) {
+	buf := make([]byte, 64)
+	var d keyDecoder
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			close(keys)
+			return
+		}
+		for _, k := range d.decodeKeys(buf[:n]) {
+			keys <- k
+		}
+	}
+}
+
+// keyDecoder holds on to the start of an arrow key's escape sequence when a
+// read splits it, to finish decoding it with the next read
+type keyDecoder struct {
+	pending []byte
+}
+
+// Arrow keys usually arrive as escape sequences in a single read, so an
+// escape on its own at the end of a read is taken to be the escape key. Once
+// the [ has arrived though, it can only be part of a sequence.
+func (d *keyDecoder) decodeKeys(read []byte) []KeyPress {
+	b := append(d.pending, read...)
+	d.pending = nil
+
+	keys := []KeyPress{}
+	for i := 0; i < len(b); i++ {
+		switch b[i] {
+		case '\x1b':
+			if i+2 == len(b) && (b[i+1] == '[' || b[i+1] == 'O') {
+				d.pending = append([]byte{}, b[i:]...)
+				return keys
+			}
+			if i+2 < len(b) && (b[i+1] == '[' || b[i+1] == 'O') {
+				switch b[i+2] {
+				case 'A':
+					keys = append(keys, KeyPress{key: KeyUp})
+				case 'B':
+					keys = append(keys, KeyPress{key: KeyDown})
+				case 'C':
+					keys = append(keys, KeyPress{key: KeyRight})
+				case 'D':
+					keys = append(keys, KeyPress{key: KeyLeft})
+				}
+				i += 2
+				continue
+			}
+			keys = append(keys, KeyPress{key: KeyEscape})
+		case '\r', '\n':
+			keys = append(keys, KeyPress{key: KeyEnter})
+		case 127, '\b':
+			keys = append(keys, KeyPress{key: KeyBackspace})
+		default:
+			keys = append(keys, KeyPress{key: KeyRune, char: rune(b[i])})
+		}
+	}
+	return keys
+}
@@ -2,13 +2,19 @@ package main
 
 import (
 	"bufio"
+	"container/heap"
+	"flag"
 	"fmt"
 	"io"
 	"os"
 	"sort"
 	"strconv"
+
+	"github.com/WJBarnes456/aoc-2022/params"
 )
 
+var top = flag.Int("top", 0, "stream the input, printing the N elves carrying the most calories instead of the parts")
+
 type Elves [][]int
 
 func getElves(r io.Reader) ([][]int, error) {
@@ -54,6 +60,125 @@ func getSums(elves Elves) []int {
 	return elves_sums
 }
 
+// ElfTotal is what's kept of an elf while streaming: which one it was (counting
+// from 0), how many items it had, and how many calories they add up to
+type ElfTotal struct {
+	Index    int `json:"index"`
+	Items    int `json:"items"`
+	Calories int `json:"calories"`
+}
+
+// forEachElf reads elves one at a time, so it never holds more than the
+// current elf's running total in memory
+func forEachElf(r io.Reader, fn func(ElfTotal)) error {
+	scanner := bufio.NewScanner(r)
+
+	current_elf := ElfTotal{}
+	for scanner.Scan() {
+		text := scanner.Text()
+		if text == "" {
+			fn(current_elf)
+			current_elf = ElfTotal{Index: current_elf.Index + 1}
+			continue
+		}
+
+		val64, err := strconv.ParseInt(text, 10, 32)
+
+		if err != nil {
+			return fmt.Errorf("failed to parse calories: %w", err)
+		}
+
+		current_elf.Items++
+		current_elf.Calories += int(val64)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read elves: %w", err)
+	}
+
+	// like getElves, the last elf counts even without a blank line after it
+	fn(current_elf)
+
+	return nil
+}
+
+// ranksBelow says whether a is carrying fewer calories than b, with ties
+// going to the earlier elf
+func ranksBelow(a, b ElfTotal) bool {
+	if a.Calories != b.Calories {
+		return a.Calories < b.Calories
+	}
+	return a.Index > b.Index
+}
+
+// A min-heap of elves by calories, so the smallest of the top N is the one to
+// drop when a bigger elf comes along
+type elfHeap []ElfTotal
+
+func (h elfHeap) Len() int           { return len(h) }
+func (h elfHeap) Less(i, j int) bool { return ranksBelow(h[i], h[j]) }
+func (h elfHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *elfHeap) Push(x any)        { *h = append(*h, x.(ElfTotal)) }
+func (h *elfHeap) Pop() any {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return last
+}
+
+// TopN keeps the N elves with the most calories seen so far, in O(log N) per
+// elf and O(N) memory
+type TopN struct {
+	n     int
+	elves elfHeap
+}
+
+func NewTopN(n int) *TopN {
+	return &TopN{n: n, elves: make(elfHeap, 0, n)}
+}
+
+func (t *TopN) Add(elf ElfTotal) {
+	if t.n <= 0 {
+		return
+	}
+
+	if len(t.elves) < t.n {
+		heap.Push(&t.elves, elf)
+		return
+	}
+
+	if ranksBelow(t.elves[0], elf) {
+		t.elves[0] = elf
+		heap.Fix(&t.elves, 0)
+	}
+}
+
+// Elves gives the top elves, most calories first
+func (t *TopN) Elves() []ElfTotal {
+	elves := make([]ElfTotal, len(t.elves))
+	copy(elves, t.elves)
+	sort.Slice(elves, func(i, j int) bool {
+		return ranksBelow(elves[j], elves[i])
+	})
+	return elves
+}
+
+func (t *TopN) Sum() int {
+	sum := 0
+	for _, elf := range t.elves {
+		sum += elf.Calories
+	}
+	return sum
+}
+
+func streamTopN(r io.Reader, top_n int) (*TopN, error) {
+	t := NewTopN(top_n)
+	if err := forEachElf(r, t.Add); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
 func part1(elves Elves) int {
 	return topN(elves, 1)
 }
@@ -63,26 +188,59 @@ func part2(elves Elves) int {
 }
 
 func topN(elves Elves, top_n int) int {
-	elves_sums := getSums(elves)
-	num_elves := len(elves_sums)
+	t := NewTopN(top_n)
+	for i, sum := range getSums(elves) {
+		t.Add(ElfTotal{i, len(elves[i]), sum})
+	}
 
-	sort.Ints(elves_sums)
+	return t.Sum()
+}
 
-	sum := 0
-	for _, value := range elves_sums[num_elves-top_n:] {
-		sum += value
+func printTop(top_n int) error {
+	t, err := streamTopN(os.Stdin, top_n)
+	if err != nil {
+		return err
 	}
 
-	return sum
+	for _, elf := range t.Elves() {
+		fmt.Printf("Elf %d (%d items): %d\n", elf.Index+1, elf.Items, elf.Calories)
+	}
+	fmt.Println("Total:", t.Sum())
+	return nil
 }
 
 func main() {
+	if err := params.Parse(); err != nil {
+		fmt.Println("Error encountered", err)
+		os.Exit(1)
+	}
+
+	if *top > 0 {
+		if err := printTop(*top); err != nil {
+			fmt.Println("Error encountered", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	elves, err := getElves(os.Stdin)
 	if err != nil {
 		fmt.Println("Error encountered", err)
 		os.Exit(1)
 	}
 
+	if *reportFormat != "" {
+		report, err := buildReport(elves, *bins, *zScore)
+		if err == nil {
+			err = report.Write(os.Stdout, *reportFormat)
+		}
+		if err != nil {
+			fmt.Println("Error encountered", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	part1 := part1(elves)
 
 	fmt.Println("Part 1 solution:", part1)
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/WJBarnes456/aoc-2022/difftest"
+)
+
+type inventory struct {
+	elves Elves
+	top_n int
+}
+
+func (i inventory) String() string {
+	lines := []string{}
+	for j, elf := range i.elves {
+		if j > 0 {
+			lines = append(lines, "")
+		}
+		for _, calories := range elf {
+			lines = append(lines, strconv.Itoa(calories))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// the top elves found by streaming the inventory's text
+func streamed(i inventory) []ElfTotal {
+	t, err := streamTopN(strings.NewReader(i.String()), i.top_n)
+	if err != nil {
+		panic(err)
+	}
+	return t.Elves()
+}
+
+// a naive reference, sorting every elf
+func referenceTop(i inventory) []ElfTotal {
+	elves := make([]ElfTotal, 0, len(i.elves))
+	for index, sum := range getSums(i.elves) {
+		elves = append(elves, ElfTotal{index, len(i.elves[index]), sum})
+	}
+	sort.SliceStable(elves, func(a, b int) bool {
+		return elves[a].Calories > elves[b].Calories
+	})
+
+	if len(elves) > i.top_n {
+		elves = elves[:i.top_n]
+	}
+	return elves
+}
+
+func TestTopNMatchesReference(t *testing.T) {
+	h := difftest.Harness[inventory, []ElfTotal]{
+		Shrink: func(i inventory) []inventory {
+			candidates := []inventory{}
+			for _, fewer := range difftest.ShrinkSlice(i.elves) {
+				candidates = append(candidates, inventory{fewer, i.top_n})
+			}
+			return candidates
+		},
+		Format: inventory.String,
+	}
+	h.Register("streamTopN", streamed)
+	h.Register("reference", referenceTop)
+
+	example := Elves{{1000, 2000, 3000}, {4000}, {5000, 6000}, {7000, 8000, 9000}, {10000}}
+	h.Check(t,
+		inventory{example, 1},
+		inventory{example, 3},
+		inventory{example, 10},
+	)
+
+	h.Generate(t, 1, 1000, func(rng *rand.Rand) inventory {
+		// few distinct calorie counts, so that ties are likely
+		elves := make(Elves, 1+rng.Intn(12))
+		for i := range elves {
+			elves[i] = make([]int, 1+rng.Intn(3))
+			for j := range elves[i] {
+				elves[i][j] = 1000 * (1 + rng.Intn(4))
+			}
+		}
+		return inventory{elves, 1 + rng.Intn(5)}
+	})
+}
+
+func TestReport(t *testing.T) {
+	example := Elves{{1000, 2000, 3000}, {4000}, {5000, 6000}, {7000, 8000, 9000}, {10000}}
+	r, err := buildReport(example, 10, 1.5)
+	if err != nil {
+		t.Fatalf("failed to build report: %v", err)
+	}
+
+	if r.Count != 5 || r.Mean != 11000 || r.Median != 10000 {
+		t.Errorf("got count %d, mean %v, median %v", r.Count, r.Mean, r.Median)
+	}
+
+	binned := 0
+	for _, b := range r.Histogram {
+		binned += b.Count
+	}
+	if binned != 5 {
+		t.Errorf("histogram holds %d elves, expected 5", binned)
+	}
+
+	if len(r.MostItems) != 2 || r.MostItems[0].Index != 0 || r.MostItems[1].Index != 3 {
+		t.Errorf("most items: %+v", r.MostItems)
+	}
+	if len(r.Outliers) != 1 || r.Outliers[0].Elf.Index != 3 {
+		t.Errorf("outliers: %+v", r.Outliers)
+	}
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	reportFormat = flag.String("report", "", "print a report of calorie statistics instead of the parts: text, csv or json")
+	bins         = flag.Int("bins", 10, "number of buckets in the report's histogram of totals")
+	zScore       = flag.Float64("zscore", 3, "how many standard deviations from the mean an elf's total must be to count as an outlier")
+)
+
+// the percentiles reported, on top of the median
+var reportedPercentiles = []float64{10, 25, 75, 90, 99}
+
+type Percentile struct {
+	Percentile float64 `json:"percentile"`
+	Value      float64 `json:"value"`
+}
+
+// Bucket counts the elves whose totals are between Low and High, inclusive
+type Bucket struct {
+	Low   int `json:"low"`
+	High  int `json:"high"`
+	Count int `json:"count"`
+}
+
+type Outlier struct {
+	Elf    ElfTotal `json:"elf"`
+	ZScore float64  `json:"z_score"`
+}
+
+// Report summarises the elves' totals. Elves are identified by their index
+// from 0 in the order they appear in the input, except in the text report,
+// which counts from 1 like --top does.
+type Report struct {
+	Count       int          `json:"count"`
+	Mean        float64      `json:"mean"`
+	Median      float64      `json:"median"`
+	StdDev      float64      `json:"std_dev"`
+	Percentiles []Percentile `json:"percentiles"`
+	Histogram   []Bucket     `json:"histogram"`
+	MostItems   []ElfTotal   `json:"most_items"`
+	FewestItems []ElfTotal   `json:"fewest_items"`
+	Threshold   float64      `json:"outlier_threshold"`
+	Outliers    []Outlier    `json:"outliers"`
+}
+
+// percentile interpolates linearly between the closest ranks of sorted
+func percentile(sorted []int, p float64) float64 {
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	fraction := rank - float64(lower)
+	return float64(sorted[lower])*(1-fraction) + float64(sorted[upper])*fraction
+}
+
+// histogram splits the range of totals into at most numBins equal-width
+// buckets
+func histogram(sorted []int, numBins int) []Bucket {
+	lowest, highest := sorted[0], sorted[len(sorted)-1]
+	width := (highest - lowest + numBins) / numBins
+
+	buckets := []Bucket{}
+	for low := lowest; low <= highest; low += width {
+		buckets = append(buckets, Bucket{low, low + width - 1, 0})
+	}
+	for _, sum := range sorted {
+		buckets[(sum-lowest)/width].Count++
+	}
+	return buckets
+}
+
+func buildReport(elves Elves, numBins int, threshold float64) (Report, error) {
+	if len(elves) == 0 {
+		return Report{}, fmt.Errorf("no elves to report on")
+	}
+	if numBins < 1 {
+		return Report{}, fmt.Errorf("histogram needs at least one bucket, not %d", numBins)
+	}
+
+	elves_sums := getSums(elves)
+	totals := make([]ElfTotal, len(elves))
+	for i, sum := range elves_sums {
+		totals[i] = ElfTotal{i, len(elves[i]), sum}
+	}
+
+	sorted := make([]int, len(elves_sums))
+	copy(sorted, elves_sums)
+	sort.Ints(sorted)
+
+	r := Report{Count: len(elves), Threshold: threshold}
+
+	total := 0
+	for _, sum := range sorted {
+		total += sum
+	}
+	r.Mean = float64(total) / float64(r.Count)
+
+	variance := 0.0
+	for _, sum := range sorted {
+		variance += (float64(sum) - r.Mean) * (float64(sum) - r.Mean)
+	}
+	r.StdDev = math.Sqrt(variance / float64(r.Count))
+
+	r.Median = percentile(sorted, 50)
+	for _, p := range reportedPercentiles {
+		r.Percentiles = append(r.Percentiles, Percentile{p, percentile(sorted, p)})
+	}
+
+	r.Histogram = histogram(sorted, numBins)
+
+	most, fewest := totals[0].Items, totals[0].Items
+	for _, elf := range totals {
+		if elf.Items > most {
+			most = elf.Items
+		}
+		if elf.Items < fewest {
+			fewest = elf.Items
+		}
+	}
+
+	r.Outliers = []Outlier{}
+	for _, elf := range totals {
+		if elf.Items == most {
+			r.MostItems = append(r.MostItems, elf)
+		}
+		if elf.Items == fewest {
+			r.FewestItems = append(r.FewestItems, elf)
+		}
+
+		// with no spread at all, nothing stands out
+		if r.StdDev == 0 {
+			continue
+		}
+		z := (float64(elf.Calories) - r.Mean) / r.StdDev
+		if math.Abs(z) > threshold {
+			r.Outliers = append(r.Outliers, Outlier{elf, z})
+		}
+	}
+
+	return r, nil
+}
+
+func elfNames(elves []ElfTotal) string {
+	names := make([]string, len(elves))
+	for i, elf := range elves {
+		names[i] = fmt.Sprintf("elf %d", elf.Index+1)
+	}
+	return strings.Join(names, ", ")
+}
+
+func (r Report) WriteText(w io.Writer) error {
+	lines := []string{
+		fmt.Sprintf("Elves: %d", r.Count),
+		fmt.Sprintf("Mean: %.2f", r.Mean),
+		fmt.Sprintf("Median: %.2f", r.Median),
+		fmt.Sprintf("Std dev: %.2f", r.StdDev),
+		"Percentiles:",
+	}
+	for _, p := range r.Percentiles {
+		lines = append(lines, fmt.Sprintf("  p%v: %.2f", p.Percentile, p.Value))
+	}
+
+	lines = append(lines, "Histogram:")
+	largest := 0
+	for _, b := range r.Histogram {
+		if b.Count > largest {
+			largest = b.Count
+		}
+	}
+	for _, b := range r.Histogram {
+		bar := strings.Repeat("#", (b.Count*40+largest-1)/largest)
+		lines = append(lines, fmt.Sprintf("  %8d - %-8d %-40s %d", b.Low, b.High, bar, b.Count))
+	}
+
+	lines = append(lines,
+		fmt.Sprintf("Most items (%d): %s", r.MostItems[0].Items, elfNames(r.MostItems)),
+		fmt.Sprintf("Fewest items (%d): %s", r.FewestItems[0].Items, elfNames(r.FewestItems)),
+		fmt.Sprintf("Outliers (|z| > %v):", r.Threshold),
+	)
+	if len(r.Outliers) == 0 {
+		lines = append(lines, "  none")
+	}
+	for _, o := range r.Outliers {
+		lines = append(lines, fmt.Sprintf("  elf %d: %d calories (z = %.2f)", o.Elf.Index+1, o.Elf.Calories, o.ZScore))
+	}
+
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
+
+// WriteCSV writes one row per statistic, as section, label and value, so the
+// different parts of the report can share a single table
+func (r Report) WriteCSV(w io.Writer) error {
+	float := func(f float64) string {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+
+	rows := [][]string{
+		{"section", "label", "value"},
+		{"summary", "count", strconv.Itoa(r.Count)},
+		{"summary", "mean", float(r.Mean)},
+		{"summary", "median", float(r.Median)},
+		{"summary", "std_dev", float(r.StdDev)},
+	}
+	for _, p := range r.Percentiles {
+		rows = append(rows, []string{"percentile", "p" + float(p.Percentile), float(p.Value)})
+	}
+	for _, b := range r.Histogram {
+		rows = append(rows, []string{"histogram", fmt.Sprintf("%d-%d", b.Low, b.High), strconv.Itoa(b.Count)})
+	}
+	for _, elf := range r.MostItems {
+		rows = append(rows, []string{"most_items", strconv.Itoa(elf.Index), strconv.Itoa(elf.Items)})
+	}
+	for _, elf := range r.FewestItems {
+		rows = append(rows, []string{"fewest_items", strconv.Itoa(elf.Index), strconv.Itoa(elf.Items)})
+	}
+	for _, o := range r.Outliers {
+		rows = append(rows, []string{"outlier", strconv.Itoa(o.Elf.Index), float(o.ZScore)})
+	}
+
+	writer := csv.NewWriter(w)
+	return writer.WriteAll(rows)
+}
+
+func (r Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+func (r Report) Write(w io.Writer, format string) error {
+	switch format {
+	case "text":
+		return r.WriteText(w)
+	case "csv":
+		return r.WriteCSV(w)
+	case "json":
+		return r.WriteJSON(w)
+	default:
+		return fmt.Errorf("unknown report format %q, expected text, csv or json", format)
+	}
+}
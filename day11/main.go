@@ -2,11 +2,20 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"io"
 	"os"
 	"sort"
 	"strings"
+
+	"github.com/WJBarnes456/aoc-2022/params"
+)
+
+var (
+	part1Rounds = flag.Int("rounds1", 20, "number of rounds to play in part 1")
+	part2Rounds = flag.Int("rounds2", 10000, "number of rounds to play in part 2")
+	relief      = flag.Int("relief", 3, "amount worry is divided by after each inspection in part 1")
 )
 
 type Operator int
@@ -71,7 +80,8 @@ func getSharedBasis(monkeys []Monkey) int {
 	return sharedBasis
 }
 
-func (m *Monkey) turn(monkeys []Monkey, part2 bool) error {
+// relief of 1 means worry levels are never reduced
+func (m *Monkey) turn(monkeys []Monkey, relief int) error {
 	sharedBasis := getSharedBasis(monkeys)
 
 	// each monkey will always end its turn with no items, so we can just iterate over
@@ -81,9 +91,7 @@ func (m *Monkey) turn(monkeys []Monkey, part2 bool) error {
 			return fmt.Errorf("failed to take turn: %v", err)
 		}
 
-		if !part2 {
-			value = value / 3
-		}
+		value = value / relief
 		value = (value + sharedBasis) % sharedBasis
 
 		divisible := (value % m.divisibilityTest) == 0
@@ -251,12 +259,12 @@ func Clone(monkeys []Monkey) []Monkey {
 	return newMonkeys
 }
 
-func part1(monkeys []Monkey) (int, error) {
+func part1(monkeys []Monkey, rounds int, relief int) (int, error) {
 	inspected := make([]int, len(monkeys))
-	for round := 0; round < 20; round++ {
+	for round := 0; round < rounds; round++ {
 		for i := range monkeys {
 			inspected[i] += len(monkeys[i].items)
-			if err := monkeys[i].turn(monkeys, false); err != nil {
+			if err := monkeys[i].turn(monkeys, relief); err != nil {
 				return 0, fmt.Errorf("error in round %d: %v", round+1, err)
 			}
 		}
@@ -266,12 +274,12 @@ func part1(monkeys []Monkey) (int, error) {
 	return inspected[len(inspected)-1] * inspected[len(inspected)-2], nil
 }
 
-func part2(monkeys []Monkey) (int, error) {
+func part2(monkeys []Monkey, rounds int) (int, error) {
 	inspected := make([]int, len(monkeys))
-	for round := 0; round < 10000; round++ {
+	for round := 0; round < rounds; round++ {
 		for i := range monkeys {
 			inspected[i] += len(monkeys[i].items)
-			if err := monkeys[i].turn(monkeys, true); err != nil {
+			if err := monkeys[i].turn(monkeys, 1); err != nil {
 				return 0, fmt.Errorf("error in round %d: %v", round+1, err)
 			}
 		}
@@ -285,6 +293,14 @@ func part2(monkeys []Monkey) (int, error) {
 }
 
 func run() error {
+	if err := params.Parse(); err != nil {
+		return fmt.Errorf("failed to parse parameters: %v", err)
+	}
+	// worry is divided by the relief, so it can't be 0
+	if *relief < 1 {
+		return fmt.Errorf("relief must be at least 1, not %d", *relief)
+	}
+
 	file, err := os.Open("input.txt")
 	if err != nil {
 		return fmt.Errorf("failed to open input.txt: %v", err)
@@ -298,13 +314,13 @@ func run() error {
 
 	fmt.Println(monkeys)
 
-	part1, err := part1(Clone(monkeys))
+	part1, err := part1(Clone(monkeys), *part1Rounds, *relief)
 	if err != nil {
 		return fmt.Errorf("failed to solve part 1: %v", err)
 	}
 	fmt.Println("Part 1:", part1)
 
-	part2, err := part2(monkeys)
+	part2, err := part2(monkeys, *part2Rounds)
 	if err != nil {
 		return fmt.Errorf("failed to solve part 2: %v", err)
 	}
@@ -2,9 +2,21 @@ package main
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
+	"io"
 	"math"
 	"os"
+
+	"github.com/WJBarnes456/aoc-2022/cache"
+	"github.com/WJBarnes456/aoc-2022/metrics"
+	"github.com/WJBarnes456/aoc-2022/params"
+)
+
+var (
+	nodesVisited = metrics.NewCounter("nodes_visited")
+	pqPushes     = metrics.NewCounter("pq_pushes")
+	pathLengths  = metrics.NewHistogram("path_length", metrics.ExponentialBounds(10, 2, 10))
 )
 
 func Abs(x int) int {
@@ -31,6 +43,8 @@ type Maze struct {
 type Puzzle struct {
 	maze   Maze
 	aNodes []*Node
+	// every node, indexed by y then x
+	nodes [][]*Node
 }
 
 func (a *Node) canTravelTo(b *Node) bool {
@@ -60,16 +74,9 @@ func buildNode(c rune, x int, y int) (Node, error) {
 	}, nil
 }
 
-func parseInput() (Puzzle, error) {
+func parseInput(r io.Reader) (Puzzle, error) {
 	// first pass: turn all the characters into nodes
-	file, err := os.Open("input.txt")
-	if err != nil {
-		return Puzzle{}, fmt.Errorf("failed to read file: %v", err)
-	}
-
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(r)
 
 	nodes := [][]*Node{}
 	aNodes := []*Node{}
@@ -156,9 +163,84 @@ func parseInput() (Puzzle, error) {
 	return Puzzle{
 		Maze{start, end},
 		aNodes,
+		nodes,
 	}, nil
 }
 
+// The wired-up nodes are full of pointer cycles, so to cache them, they're
+// flattened into nodes referring to each other by index in reading order
+type FlatPuzzle struct {
+	Heights    [][]int
+	Neighbours [][]int
+	Start      int
+	End        int
+	ANodes     []int
+}
+
+func (p Puzzle) flatten() FlatPuzzle {
+	indices := map[*Node]int{}
+	flat := FlatPuzzle{Heights: make([][]int, len(p.nodes))}
+	for y, row := range p.nodes {
+		flat.Heights[y] = make([]int, len(row))
+		for x, node := range row {
+			indices[node] = len(indices)
+			flat.Heights[y][x] = node.height
+		}
+	}
+
+	flat.Neighbours = make([][]int, len(indices))
+	for _, row := range p.nodes {
+		for _, node := range row {
+			neighbours := make([]int, len(node.neighbours))
+			for i, neighbour := range node.neighbours {
+				neighbours[i] = indices[neighbour]
+			}
+			flat.Neighbours[indices[node]] = neighbours
+		}
+	}
+
+	flat.Start, flat.End = indices[p.maze.start], indices[p.maze.end]
+	flat.ANodes = make([]int, len(p.aNodes))
+	for i, aNode := range p.aNodes {
+		flat.ANodes[i] = indices[aNode]
+	}
+	return flat
+}
+
+func (f FlatPuzzle) puzzle() Puzzle {
+	// first pass: make all the nodes, so the neighbours have something to point at
+	nodes := make([][]*Node, len(f.Heights))
+	flatNodes := []*Node{}
+	for y, row := range f.Heights {
+		nodes[y] = make([]*Node, len(row))
+		for x, height := range row {
+			node := &Node{height, nil, x, y}
+			nodes[y][x] = node
+			flatNodes = append(flatNodes, node)
+		}
+	}
+
+	// second pass: connect them up
+	for i, neighbours := range f.Neighbours {
+		node := flatNodes[i]
+		node.neighbours = make([]*Node, len(neighbours))
+		for j, neighbour := range neighbours {
+			node.neighbours[j] = flatNodes[neighbour]
+		}
+	}
+
+	aNodes := make([]*Node, len(f.ANodes))
+	for i, aNode := range f.ANodes {
+		aNodes[i] = flatNodes[aNode]
+	}
+
+	return Puzzle{
+		Maze{flatNodes[f.Start], flatNodes[f.End]},
+		aNodes,
+		nodes,
+	}
+}
+
 // yes, my priority queue is a linked list. problem?
 type PriorityQueue struct {
 	head *ScoredNode
@@ -242,6 +324,7 @@ func (p *PriorityQueue) update(sn ScoredNode) {
 
 	// adding to an empty list
 	if temp == nil {
+		pqPushes.Inc()
 		p.head = &sn
 		return
 	}
@@ -267,6 +350,7 @@ func (p *PriorityQueue) update(sn ScoredNode) {
 		p.remove(existing)
 	}
 
+	pqPushes.Inc()
 	p.insert(sn)
 }
 
@@ -287,6 +371,7 @@ func solve(m Maze) ([]*Node, error) {
 		// pop from the queue
 		scoredNode := priorityQueue.head
 		priorityQueue.head = priorityQueue.head.next
+		nodesVisited.Inc()
 
 		//fmt.Printf("visiting node %p\n", scoredNode.node)
 
@@ -298,6 +383,7 @@ func solve(m Maze) ([]*Node, error) {
 				revPath = append(revPath, temp.node)
 				temp = temp.pathPrev
 			}
+			pathLengths.Observe(float64(len(revPath) - 1))
 			return revPath, nil
 		}
 
@@ -362,10 +448,27 @@ func part2(p Puzzle) (int, error) {
 }
 
 func run() error {
-	puzzle, err := parseInput()
+	if err := params.Parse(); err != nil {
+		return fmt.Errorf("failed to parse parameters: %v", err)
+	}
+	if err := metrics.Start(); err != nil {
+		return err
+	}
+	defer metrics.Finish()
+
+	input, err := os.ReadFile("input.txt")
+	if err != nil {
+		return fmt.Errorf("failed to read file: %v", err)
+	}
+
+	flatPuzzle, err := cache.Load("day12-puzzle", func() (FlatPuzzle, error) {
+		puzzle, err := parseInput(bytes.NewReader(input))
+		return puzzle.flatten(), err
+	}, input)
 	if err != nil {
 		return fmt.Errorf("failed to parse input: %v", err)
 	}
+	puzzle := flatPuzzle.puzzle()
 
 	part1, err := part1(puzzle)
 
@@ -7,6 +7,15 @@ import (
 	"math"
 	"os"
 	"strings"
+
+	"github.com/WJBarnes456/aoc-2022/frames"
+	"github.com/WJBarnes456/aoc-2022/metrics"
+	"github.com/WJBarnes456/aoc-2022/params"
+)
+
+var (
+	grainsSimulated = metrics.NewCounter("grains_simulated")
+	grainSteps      = metrics.NewHistogram("steps_per_grain", metrics.ExponentialBounds(1, 2, 10))
 )
 
 // Sparse array to keep track of what space is filled
@@ -77,8 +86,10 @@ func (w *World) fillLine(startX int, startY int, endX int, endY int) error {
 
 // Adds sand to the world, returning whether sand was actually added
 func (w *World) addSand(floor bool) bool {
+	grainsSimulated.Inc()
 	sandX := 500
 	sandY := 0
+	steps := 0
 
 	if w.filled[sandY] != nil {
 		_, sourceBlocked := w.filled[sandY][sandX]
@@ -88,7 +99,7 @@ func (w *World) addSand(floor bool) bool {
 	}
 
 	lowest := w.lowestPoint()
-	for sandY < lowest+2 {
+	for ; sandY < lowest+2; steps++ {
 		// kind of nasty, but it should work - only check if you're not currently trying to place on the floor
 		if !(floor && sandY == lowest+1) {
 			nextY := sandY + 1
@@ -132,6 +143,7 @@ func (w *World) addSand(floor bool) bool {
 		}
 
 		w.filled[sandY][sandX] = Sand
+		grainSteps.Observe(float64(steps))
 		return true
 	}
 	return false
@@ -149,6 +161,45 @@ func (w *World) Clone() *World {
 	return &World{newWorld, w.lowestRock}
 }
 
+// Draws everything filled so far, in the same style as the puzzle
+func (w *World) render(floor bool) []string {
+	minX, maxX := 500, 500
+	for _, row := range w.filled {
+		for x := range row {
+			if x < minX {
+				minX = x
+			}
+			if x > maxX {
+				maxX = x
+			}
+		}
+	}
+
+	lines := make([]string, 0, w.lowestRock+3)
+	for y := 0; y <= w.lowestRock+1; y++ {
+		line := make([]byte, 0, maxX-minX+1)
+		for x := minX; x <= maxX; x++ {
+			material, filled := w.filled[y][x]
+			switch {
+			case !filled && x == 500 && y == 0:
+				line = append(line, '+')
+			case !filled:
+				line = append(line, '.')
+			case material == Rock:
+				line = append(line, '#')
+			default:
+				line = append(line, 'o')
+			}
+		}
+		lines = append(lines, string(line))
+	}
+
+	if floor {
+		lines = append(lines, strings.Repeat("#", maxX-minX+1))
+	}
+	return lines
+}
+
 func parseInput(r io.Reader) (*World, error) {
 	scanner := bufio.NewScanner(r)
 	world := World{map[int]map[int]Material{}, math.MinInt}
@@ -172,23 +223,42 @@ func parseInput(r io.Reader) (*World, error) {
 	return &world, nil
 }
 
-func part1(w *World) int {
+func part1(w *World, fw *frames.Writer) (int, error) {
 	count := 0
 	for w.addSand(false) {
 		count += 1
+		if fw != nil {
+			if err := fw.Emit(fmt.Sprintf("Part 1: %d grains of sand at rest", count), w.render(false)); err != nil {
+				return 0, fmt.Errorf("failed to emit frame: %v", err)
+			}
+		}
 	}
-	return count
+	return count, nil
 }
 
-func part2(w *World) int {
+func part2(w *World, fw *frames.Writer) (int, error) {
 	count := 0
 	for w.addSand(true) {
 		count += 1
+		if fw != nil {
+			if err := fw.Emit(fmt.Sprintf("Part 2: %d grains of sand at rest", count), w.render(true)); err != nil {
+				return 0, fmt.Errorf("failed to emit frame: %v", err)
+			}
+		}
 	}
-	return count
+	return count, nil
 }
 
 func run() error {
+	if err := params.Parse(); err != nil {
+		return fmt.Errorf("failed to parse parameters: %v", err)
+	}
+	if err := metrics.Start(); err != nil {
+		return err
+	}
+	defer metrics.Finish()
+	fw := frames.New()
+
 	file, err := os.Open("input.txt")
 	if err != nil {
 		return fmt.Errorf("failed to open input: %v", err)
@@ -200,11 +270,22 @@ func run() error {
 		return fmt.Errorf("failed to parse world: %v", err)
 	}
 
-	fmt.Println(world.filled)
+	// the raw map would get in the way of the frames
+	if fw == nil {
+		fmt.Println(world.filled)
+	}
 
-	fmt.Println("Part 1:", part1(world.Clone()))
+	part1, err := part1(world.Clone(), fw)
+	if err != nil {
+		return fmt.Errorf("failed to solve part 1: %v", err)
+	}
+	fmt.Println("Part 1:", part1)
 
-	fmt.Println("Part 2:", part2(world.Clone()))
+	part2, err := part2(world.Clone(), fw)
+	if err != nil {
+		return fmt.Errorf("failed to solve part 2: %v", err)
+	}
+	fmt.Println("Part 2:", part2)
 	return nil
 }
 
@@ -2,10 +2,19 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"io"
 	"os"
 	"sort"
+
+	"github.com/WJBarnes456/aoc-2022/params"
+)
+
+// The worked example uses row=10 and bound=20
+var (
+	row   = flag.Int("row", 2000000, "row on which to count blocked positions for part 1")
+	bound = flag.Int("bound", 4000000, "largest x and y coordinate the distress beacon can have for part 2")
 )
 
 type SensorBeacon struct {
@@ -139,8 +148,8 @@ func findBlocked(sbs []SensorBeacon, lineY int) []Range {
 	return newRanges
 }
 
-func part1(sbs []SensorBeacon) int {
-	ranges := findBlocked(sbs, 2000000)
+func part1(sbs []SensorBeacon, row int) int {
+	ranges := findBlocked(sbs, row)
 	// total up the length of ranges
 	total := 0
 	for _, r := range ranges {
@@ -150,26 +159,27 @@ func part1(sbs []SensorBeacon) int {
 	// subtract any beacons which are actually on that line
 	beacons := getBeacons(sbs)
 	for _, beacon := range beacons {
-		if beacon.y == 2000000 {
+		if beacon.y == row {
 			total--
 		}
 	}
 	return total
 }
 
-func part2(sbs []SensorBeacon) int {
+func part2(sbs []SensorBeacon, bound int) int {
 	result := make(chan int)
-	for y := 0; y <= 4000000; y++ {
+	for y := 0; y <= bound; y++ {
 		go func(lineY int) {
 			blocked := findBlocked(sbs, lineY)
 			for _, r := range blocked {
-				if r.start <= 0 && r.end >= 4000000 {
+				if r.start <= 0 && r.end >= bound {
 					return
 				}
 			}
 			// this is where the beacon must be
 			x := blocked[0].end + 1
 			fmt.Println("Found on y=", lineY, ":", blocked)
+			// the tuning frequency multiplier doesn't change with the bound
 			result <- 4000000*x + lineY
 		}(y)
 	}
@@ -177,6 +187,10 @@ func part2(sbs []SensorBeacon) int {
 }
 
 func run() error {
+	if err := params.Parse(); err != nil {
+		return fmt.Errorf("failed to parse parameters: %v", err)
+	}
+
 	file, err := os.Open("input.txt")
 	if err != nil {
 		return fmt.Errorf("failed to open input file")
@@ -188,8 +202,8 @@ func run() error {
 		return fmt.Errorf("failed to parse input: %v", err)
 	}
 
-	fmt.Println("Part 1:", part1(data))
-	fmt.Println("Part 2:", part2(data))
+	fmt.Println("Part 1:", part1(data, *row))
+	fmt.Println("Part 2:", part2(data, *bound))
 
 	return nil
 }
@@ -2,13 +2,32 @@ package main
 
 import (
 	"bufio"
+	"bytes"
 	"container/heap"
+	"flag"
 	"fmt"
 	"io"
 	"os"
 	"regexp"
 	"sort"
 	"strings"
+
+	"github.com/WJBarnes456/aoc-2022/cache"
+	"github.com/WJBarnes456/aoc-2022/metrics"
+	"github.com/WJBarnes456/aoc-2022/params"
+)
+
+var (
+	startValve   = flag.String("start", "AA", "valve you start at")
+	part1Minutes = flag.Int("minutes1", 30, "minutes before the volcano erupts in part 1")
+	part2Minutes = flag.Int("minutes2", 26, "minutes left after teaching the elephant in part 2")
+)
+
+var (
+	statesVisited = metrics.NewCounter("states_visited")
+	memoHits      = metrics.NewCounter("memo_hits")
+	pqPushes      = metrics.NewCounter("pq_pushes")
+	moveCombos    = metrics.NewHistogram("move_combinations", metrics.ExponentialBounds(1, 2, 10))
 )
 
 type Valve struct {
@@ -172,8 +191,10 @@ func (m *Memo) score(valves map[string]*Valve, shortestPaths map[string]map[stri
 	state := m.getState(occupiedValves, openValves, timeRemaining)
 	value, alreadyCalculated := (*m)[state]
 	if alreadyCalculated {
+		memoHits.Inc()
 		return value
 	}
+	statesVisited.Inc()
 
 	if timeRemaining == 0 {
 		return 0
@@ -219,6 +240,7 @@ func (m *Memo) score(valves map[string]*Valve, shortestPaths map[string]map[stri
 	bestScore := timeRemaining * roundScore
 
 	combos := allMoveCombinations(allAgentMoves)
+	moveCombos.Observe(float64(len(combos)))
 	for _, moveCombo := range combos {
 		nextPositions := make([]string, len(moveCombo))
 		valvesToOpen := make([]*string, len(moveCombo))
@@ -236,15 +258,15 @@ func (m *Memo) score(valves map[string]*Valve, shortestPaths map[string]map[stri
 	return value
 }
 
-func part1(valves map[string]*Valve, shortestPaths map[string]map[string][]string) int {
+func part1(valves map[string]*Valve, shortestPaths map[string]map[string][]string, start string, minutes int) int {
 	memo := Memo(map[State]int{})
-	score := memo.score(valves, shortestPaths, []string{"AA"}, map[string]*Valve{}, 30)
+	score := memo.score(valves, shortestPaths, []string{start}, map[string]*Valve{}, minutes)
 	return score
 }
 
-func part2(valves map[string]*Valve, shortestPaths map[string]map[string][]string) int {
+func part2(valves map[string]*Valve, shortestPaths map[string]map[string][]string, start string, minutes int) int {
 	memo := Memo(map[State]int{})
-	return memo.score(valves, shortestPaths, []string{"AA", "AA"}, map[string]*Valve{}, 26)
+	return memo.score(valves, shortestPaths, []string{start, start}, map[string]*Valve{}, minutes)
 }
 
 func getShortestPaths(valves map[string]*Valve, targetValve *Valve) map[string][]string {
@@ -254,6 +276,7 @@ func getShortestPaths(valves map[string]*Valve, targetValve *Valve) map[string][
 	pq := PriorityQueue{}
 	shortestPaths[targetValve.name] = []string{}
 	heap.Push(&pq, &Item{value: targetValve, distance: 0})
+	pqPushes.Inc()
 	for len(pq) > 0 {
 		item := heap.Pop(&pq).(*Item)
 
@@ -269,6 +292,7 @@ func getShortestPaths(valves map[string]*Valve, targetValve *Valve) map[string][
 				shortestPaths[neighbour.name] = []string{valve.name}
 				shortestPaths[neighbour.name] = append(shortestPaths[neighbour.name], shortestPaths[valve.name]...)
 				heap.Push(&pq, &Item{value: neighbour, distance: len(shortestPaths[neighbour.name])})
+				pqPushes.Inc()
 			}
 		}
 		visited[valve] = struct{}{}
@@ -289,13 +313,21 @@ func getAllShortestPaths(valves map[string]*Valve) map[string]map[string][]strin
 }
 
 func run() error {
-	file, err := os.Open("input.txt")
+	if err := params.Parse(); err != nil {
+		return fmt.Errorf("failed to parse parameters: %v", err)
+	}
+	if err := metrics.Start(); err != nil {
+		return err
+	}
+	defer metrics.Finish()
+
+	// read the whole file up front, as the cache is keyed by its contents
+	input, err := os.ReadFile("input.txt")
 	if err != nil {
 		return fmt.Errorf("failed to open input file: %v", err)
 	}
-	defer file.Close()
 
-	valves, err := parseInput(file)
+	valves, err := parseInput(bytes.NewReader(input))
 	if err != nil {
 		return fmt.Errorf("failed to parse valves: %v", err)
 	}
@@ -305,11 +337,20 @@ func run() error {
 		fmt.Println(valve)
 	}
 
-	shortestPaths := getAllShortestPaths(valves)
+	if _, exists := valves[*startValve]; !exists {
+		return fmt.Errorf("start valve %s not in input", *startValve)
+	}
+
+	shortestPaths, err := cache.Load("day16-shortest-paths", func() (map[string]map[string][]string, error) {
+		return getAllShortestPaths(valves), nil
+	}, input)
+	if err != nil {
+		return fmt.Errorf("failed to find shortest paths: %v", err)
+	}
 
-	fmt.Println("Part 1:", part1(valves, shortestPaths))
+	fmt.Println("Part 1:", part1(valves, shortestPaths, *startValve, *part1Minutes))
 
-	fmt.Println("Part 2:", part2(valves, shortestPaths))
+	fmt.Println("Part 2:", part2(valves, shortestPaths, *startValve, *part2Minutes))
 
 	return nil
 }
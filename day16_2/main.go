@@ -2,13 +2,32 @@ package main
 
 import (
 	"bufio"
+	"bytes"
 	"container/heap"
+	"flag"
 	"fmt"
 	"io"
 	"os"
 	"regexp"
 	"sort"
 	"strings"
+
+	"github.com/WJBarnes456/aoc-2022/cache"
+	"github.com/WJBarnes456/aoc-2022/metrics"
+	"github.com/WJBarnes456/aoc-2022/params"
+)
+
+var (
+	startValve   = flag.String("start", "AA", "valve you start at")
+	part1Minutes = flag.Int("minutes1", 30, "minutes before the volcano erupts in part 1")
+	part2Minutes = flag.Int("minutes2", 26, "minutes left after teaching the elephant in part 2")
+)
+
+var (
+	statesVisited = metrics.NewCounter("states_visited")
+	memoHits      = metrics.NewCounter("memo_hits")
+	pqPushes      = metrics.NewCounter("pq_pushes")
+	divisions     = metrics.NewCounter("divisions_considered")
 )
 
 // A re-implementation of day16, with a couple of key optimisations:
@@ -68,8 +87,10 @@ type Memo map[State]int
 func (m *Memo) score(g Graph, currentNode *Node, openValves map[string]struct{}, timeRemaining int) int {
 	state := linearise(currentNode, openValves, timeRemaining)
 	if value, alreadyComputed := (*m)[state]; alreadyComputed {
+		memoHits.Inc()
 		return value
 	}
+	statesVisited.Inc()
 
 	nodeScore := timeRemaining * currentNode.flowRate
 	newOpenValves := make(map[string]struct{}, len(openValves)+1)
@@ -95,12 +116,12 @@ func (m *Memo) score(g Graph, currentNode *Node, openValves map[string]struct{},
 	return value
 }
 
-func (g Graph) part1() int {
+func (g Graph) part1(minutes int) int {
 	memo := Memo{}
-	return memo.score(g, g.start, map[string]struct{}{}, 30)
+	return memo.score(g, g.start, map[string]struct{}{}, minutes)
 }
 
-func (g Graph) part2() int {
+func (g Graph) part2(minutes int) int {
 	memo := Memo{}
 	dividedNodes := make([]*Node, 0, len(g.nodes))
 	for _, node := range g.nodes {
@@ -115,6 +136,7 @@ func (g Graph) part2() int {
 	// this is memoised on the same memo (!!), because the situations are otherwise the same!
 	best := 0
 	for _, division := range generateAllDivisions(dividedNodes) {
+		divisions.Inc()
 		youBlocked := map[string]struct{}{}
 		for _, name := range division[0] {
 			youBlocked[name] = struct{}{}
@@ -125,7 +147,7 @@ func (g Graph) part2() int {
 			elBlocked[name] = struct{}{}
 		}
 
-		score := memo.score(g, g.start, youBlocked, 26) + memo.score(g, g.start, elBlocked, 26)
+		score := memo.score(g, g.start, youBlocked, minutes) + memo.score(g, g.start, elBlocked, minutes)
 		if score > best {
 			best = score
 		}
@@ -157,7 +179,7 @@ func generateAllDivisions(nodes []*Node) [][][]string {
 	return divisions
 }
 
-func (v *Valves) graphify() Graph {
+func (v *Valves) graphify(start string) Graph {
 	shortestPaths := getAllShortestPaths(*v)
 
 	nodes := make(map[string]*Node, len(shortestPaths)+1)
@@ -168,9 +190,9 @@ func (v *Valves) graphify() Graph {
 		nodes[valve.name] = &Node{valve.name, valve.flowRate, []Edge{}}
 	}
 
-	_, startIsUseful := nodes["AA"]
+	_, startIsUseful := nodes[start]
 	if !startIsUseful {
-		nodes["AA"] = &Node{"AA", 0, []Edge{}}
+		nodes[start] = &Node{start, 0, []Edge{}}
 	}
 
 	// second pass: add the edges
@@ -192,7 +214,7 @@ func (v *Valves) graphify() Graph {
 	}
 
 	// final pass: flatten the map, as the names are no longer important
-	startNode := nodes["AA"]
+	startNode := nodes[start]
 	outNodes := make([]*Node, 0, len(nodes))
 	for _, node := range nodes {
 		outNodes = append(outNodes, node)
@@ -204,6 +226,60 @@ func (v *Valves) graphify() Graph {
 	}
 }
 
+// The graph is full of pointer cycles, so to cache it, it's flattened into
+// nodes referring to each other by index
+type FlatGraph struct {
+	Names     []string
+	FlowRates []int
+	Edges     [][]FlatEdge
+	Start     int
+}
+
+type FlatEdge struct {
+	TimeCost int
+	Dest     int
+}
+
+func (g Graph) flatten() FlatGraph {
+	indices := make(map[*Node]int, len(g.nodes))
+	for i, node := range g.nodes {
+		indices[node] = i
+	}
+
+	flat := FlatGraph{
+		Names:     make([]string, len(g.nodes)),
+		FlowRates: make([]int, len(g.nodes)),
+		Edges:     make([][]FlatEdge, len(g.nodes)),
+		Start:     indices[g.start],
+	}
+	for i, node := range g.nodes {
+		flat.Names[i] = node.name
+		flat.FlowRates[i] = node.flowRate
+		flat.Edges[i] = make([]FlatEdge, len(node.edges))
+		for j, edge := range node.edges {
+			flat.Edges[i][j] = FlatEdge{edge.timeCost, indices[edge.dest]}
+		}
+	}
+	return flat
+}
+
+func (f FlatGraph) graph() Graph {
+	// first pass: make all the nodes, so the edges have something to point at
+	nodes := make([]*Node, len(f.Names))
+	for i, name := range f.Names {
+		nodes[i] = &Node{name, f.FlowRates[i], make([]Edge, 0, len(f.Edges[i]))}
+	}
+
+	// second pass: add the edges
+	for i, edges := range f.Edges {
+		for _, edge := range edges {
+			nodes[i].edges = append(nodes[i].edges, Edge{edge.TimeCost, nodes[edge.Dest]})
+		}
+	}
+
+	return Graph{nodes: nodes, start: nodes[f.Start]}
+}
+
 func parseInput(r io.Reader) (Valves, error) {
 	scanner := bufio.NewScanner(r)
 	nameToValve := map[string]*Valve{}
@@ -256,6 +332,7 @@ func getShortestPaths(valves Valves, targetValve *Valve) map[string][]string {
 	pq := PriorityQueue{}
 	shortestPaths[targetValve.name] = []string{}
 	heap.Push(&pq, &Item{value: targetValve, distance: 0})
+	pqPushes.Inc()
 	for len(pq) > 0 {
 		item := heap.Pop(&pq).(*Item)
 
@@ -271,6 +348,7 @@ func getShortestPaths(valves Valves, targetValve *Valve) map[string][]string {
 				shortestPaths[neighbour.name] = []string{valve.name}
 				shortestPaths[neighbour.name] = append(shortestPaths[neighbour.name], shortestPaths[valve.name]...)
 				heap.Push(&pq, &Item{value: neighbour, distance: len(shortestPaths[neighbour.name])})
+				pqPushes.Inc()
 			}
 		}
 		visited[valve] = struct{}{}
@@ -291,22 +369,40 @@ func getAllShortestPaths(valves Valves) map[string]map[string][]string {
 }
 
 func run() error {
-	file, err := os.Open("input.txt")
+	if err := params.Parse(); err != nil {
+		return fmt.Errorf("failed to parse parameters: %v", err)
+	}
+	if err := metrics.Start(); err != nil {
+		return err
+	}
+	defer metrics.Finish()
+
+	// read the whole file up front, as the cache is keyed by its contents
+	input, err := os.ReadFile("input.txt")
 	if err != nil {
 		return fmt.Errorf("failed to open input file: %v", err)
 	}
-	defer file.Close()
 
-	valves, err := parseInput(file)
+	valves, err := parseInput(bytes.NewReader(input))
 	if err != nil {
 		return fmt.Errorf("failed to parse valves: %v", err)
 	}
 
-	graph := valves.graphify()
+	if _, exists := valves[*startValve]; !exists {
+		return fmt.Errorf("start valve %s not in input", *startValve)
+	}
+
+	flatGraph, err := cache.Load("day16_2-graph", func() (FlatGraph, error) {
+		return valves.graphify(*startValve).flatten(), nil
+	}, input, []byte(*startValve))
+	if err != nil {
+		return fmt.Errorf("failed to build graph: %v", err)
+	}
+	graph := flatGraph.graph()
 
-	fmt.Println("Part 1:", graph.part1())
+	fmt.Println("Part 1:", graph.part1(*part1Minutes))
 
-	fmt.Println("Part 2:", graph.part2())
+	fmt.Println("Part 2:", graph.part2(*part2Minutes))
 
 	return nil
 }
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/WJBarnes456/aoc-2022/difftest"
+)
+
+// day16 and this rewrite use very different algorithms, so check them
+// against each other as whole programs
+func TestAgreesWithDay16(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds and runs both days")
+	}
+
+	example, err := os.ReadFile("testdata/example.txt")
+	if err != nil {
+		t.Fatalf("failed to read example: %v", err)
+	}
+
+	day16, day16_2 := difftest.Binary(t, "../day16"), difftest.Binary(t, ".")
+
+	h := difftest.Harness[string, map[int]string]{}
+	h.Register("day16", day16)
+	h.Register("day16_2", day16_2)
+	h.Check(t, string(example))
+
+	// agreeing isn't much use if they're both wrong, so check the worked
+	// example's answers too
+	expected := map[int]string{1: "1651", 2: "1707"}
+	if answers := day16_2(string(example)); !reflect.DeepEqual(answers, expected) {
+		t.Errorf("answered %v, expected %v", answers, expected)
+	}
+}
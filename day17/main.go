@@ -1,9 +1,19 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"strings"
+
+	"github.com/WJBarnes456/aoc-2022/frames"
+	"github.com/WJBarnes456/aoc-2022/params"
+)
+
+var (
+	jetString  = flag.String("jets", ">>><<><>><<<>><>>><<<>>><<<><<<>><>><<>>", "jet pattern to simulate")
+	part1Rocks = flag.Int("rocks1", 2022, "number of rocks to drop in part 1")
+	part2Rocks = flag.Int("rocks2", 1000000000000, "number of rocks to drop in part 2")
 )
 
 const CHAMBER_WIDTH = 7
@@ -202,6 +212,29 @@ func (c *Chamber) AddRock(class ShapeClass) error {
 	return nil
 }
 
+// Only the top of the tower is drawn, as that's the only part that changes
+const VIEW_ROWS = 30
+
+func (c *Chamber) render() []string {
+	lines := make([]string, 0, VIEW_ROWS+1)
+	top := c.MaxHeight()
+	bottom := top - VIEW_ROWS + 1
+	for y := top; y >= bottom && y >= 0; y-- {
+		line := []byte("|.......|")
+		for x, present := range c.occupancy[y] {
+			if present {
+				line[x+1] = '#'
+			}
+		}
+		lines = append(lines, string(line))
+	}
+
+	if bottom <= 0 {
+		lines = append(lines, "+-------+")
+	}
+	return lines
+}
+
 func parseInput(input string) ([]Move, error) {
 	out := make([]Move, len(input))
 	for i, c := range []rune(input) {
@@ -217,22 +250,27 @@ func parseInput(input string) ([]Move, error) {
 	return out, nil
 }
 
-func part1(jets []Move) int {
+func part1(jets []Move, rocks int, fw *frames.Writer) (int, error) {
 	chamber := Chamber{
 		map[int][]bool{},
 		jets,
 		0,
 	}
 
-	for i := 0; i < 2022; i++ {
+	for i := 0; i < rocks; i++ {
 		shapeClass := ShapeClass(i % 5)
 		chamber.AddRock(shapeClass)
+		if fw != nil {
+			if err := fw.Emit(fmt.Sprintf("Part 1: rock %d/%d, height %d", i+1, rocks, chamber.MaxHeight()+1), chamber.render()); err != nil {
+				return 0, fmt.Errorf("failed to emit frame: %v", err)
+			}
+		}
 	}
 
-	return chamber.MaxHeight() + 1
+	return chamber.MaxHeight() + 1, nil
 }
 
-func part2(jets []Move) int {
+func part2(jets []Move, rocks int, fw *frames.Writer) (int, error) {
 	chamber := Chamber{
 		map[int][]bool{},
 		jets,
@@ -246,8 +284,7 @@ func part2(jets []Move) int {
 	skipped := false
 	memo := map[ChamberState]GameState{}
 
-	max := 1000000000000
-	for i := 0; i < max; i++ {
+	for i := 0; i < rocks; i++ {
 		shapeClass := ShapeClass(i % 5)
 
 		chamberState := ChamberState{chamber.Profile(), shapeClass, chamber.jetIndex}
@@ -255,7 +292,7 @@ func part2(jets []Move) int {
 		if !skipped {
 			if val, exists := memo[chamberState]; exists {
 				fmt.Printf("Hit a conflict at turn %v, previously saw state at turn %v\n", i, val.turn)
-				remainingTurns := max - i
+				remainingTurns := rocks - i
 				cycleLength := i - val.turn
 				heightChange := chamber.MaxHeight() + heightDiff - val.height
 				fmt.Printf("Cycle length %v turns, height change %v\n", cycleLength, heightChange)
@@ -268,20 +305,39 @@ func part2(jets []Move) int {
 			memo[chamberState] = GameState{height: chamber.MaxHeight() + heightDiff, turn: i}
 		}
 		chamber.AddRock(shapeClass)
+		if fw != nil {
+			if err := fw.Emit(fmt.Sprintf("Part 2: rock %d/%d, height %d", i+1, rocks, chamber.MaxHeight()+heightDiff), chamber.render()); err != nil {
+				return 0, fmt.Errorf("failed to emit frame: %v", err)
+			}
+		}
 	}
 
-	return chamber.MaxHeight() + heightDiff
+	return chamber.MaxHeight() + heightDiff, nil
 }
 
 func run() error {
-	input, err := parseInput(">>><<><>><<<>><>>><<<>>><<<><<<>><>><<>>")
+	if err := params.Parse(); err != nil {
+		return fmt.Errorf("failed to parse parameters: %v", err)
+	}
+
+	fw := frames.New()
+
+	input, err := parseInput(*jetString)
 	if err != nil {
 		return fmt.Errorf("failed to parse jet string: %v", err)
 	}
 
-	fmt.Println("Part 1:", part1(input))
+	part1, err := part1(input, *part1Rocks, fw)
+	if err != nil {
+		return fmt.Errorf("failed to solve part 1: %v", err)
+	}
+	fmt.Println("Part 1:", part1)
 
-	fmt.Println("Part 2:", part2(input))
+	part2, err := part2(input, *part2Rocks, fw)
+	if err != nil {
+		return fmt.Errorf("failed to solve part 2: %v", err)
+	}
+	fmt.Println("Part 2:", part2)
 
 	return nil
 }
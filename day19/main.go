@@ -2,10 +2,28 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"io"
 	"math"
 	"os"
+
+	"github.com/WJBarnes456/aoc-2022/metrics"
+	"github.com/WJBarnes456/aoc-2022/params"
+)
+
+var (
+	part1Minutes    = flag.Int("minutes1", 24, "minutes available to open geodes in part 1")
+	part2Minutes    = flag.Int("minutes2", 32, "minutes available to open geodes in part 2")
+	part2Blueprints = flag.Int("blueprints2", 3, "number of blueprints the elephants didn't eat in part 2")
+)
+
+var (
+	statesVisited  = metrics.NewCounter("states_visited")
+	memoHits       = metrics.NewCounter("memo_hits")
+	prunedBranches = metrics.NewCounter("pruned_branches")
+	// the memo is per blueprint, so its size is how many states each one needed
+	memoSizes = metrics.NewHistogram("memo_size_per_blueprint", metrics.ExponentialBounds(1000, 4, 10))
 )
 
 // we could make this a map, but using a struct instead, i.e. a value type,
@@ -132,8 +150,10 @@ func (s *State) StateAfterBuilding(botCost Resources, botType Resource) State {
 func (m *Memo) maxGeodes(s State, bestSoFar *int) int {
 	// look up in memo if present
 	if val, exists := (*m)[s]; exists {
+		memoHits.Inc()
 		return val
 	}
+	statesVisited.Inc()
 
 	// base geodes is the number we will make in the remaining time
 	score := s.resources.geodes + s.bots.geodes*s.timeRemaining
@@ -147,6 +167,7 @@ func (m *Memo) maxGeodes(s State, bestSoFar *int) int {
 	// best case scenario, we build another geode bot every turn, so result is timeRemaining -1 + timeRemaining-2 + ... + 1
 	// i.e. t(t-1)/2
 	if score+(s.timeRemaining*(s.timeRemaining-1))/2 < *bestSoFar {
+		prunedBranches.Inc()
 		return score
 	}
 
@@ -225,30 +246,32 @@ func (m *Memo) maxGeodes(s State, bestSoFar *int) int {
 func (b *Blueprint) maxGeodes(startState State) int {
 	memo := make(Memo)
 	best := 0
-	return memo.maxGeodes(startState, &best)
+	geodes := memo.maxGeodes(startState, &best)
+	memoSizes.Observe(float64(len(memo)))
+	return geodes
 }
 
 func (b *Blueprint) qualityScore(startState State) int {
 	return b.number * b.maxGeodes(startState)
 }
 
-func part1_worker(blueprints <-chan *Blueprint, scores chan<- int) {
+func part1_worker(blueprints <-chan *Blueprint, scores chan<- int, minutes int) {
 	for b := range blueprints {
 		startState := State{
 			blueprint:     b,
 			bots:          Resources{ore: 1},
-			timeRemaining: 24,
+			timeRemaining: minutes,
 		}
 		scores <- b.qualityScore(startState)
 	}
 }
 
-func part1(blueprints []*Blueprint) int {
+func part1(blueprints []*Blueprint, minutes int) int {
 	scores := make(chan int, len(blueprints))
 	jobs := make(chan *Blueprint, len(blueprints))
 
 	for i := 0; i < 8; i++ {
-		go part1_worker(jobs, scores)
+		go part1_worker(jobs, scores, minutes)
 	}
 
 	for _, blueprint := range blueprints {
@@ -266,12 +289,17 @@ func part1(blueprints []*Blueprint) int {
 	return sum
 }
 
-func part2(blueprints []*Blueprint) int {
+func part2(blueprints []*Blueprint, minutes int, count int) int {
+	// the worked example only has two blueprints
+	if count > len(blueprints) {
+		count = len(blueprints)
+	}
+
 	total := 1
-	for _, blueprint := range blueprints[:3] {
+	for _, blueprint := range blueprints[:count] {
 		startState := State{
 			blueprint:     blueprint,
-			timeRemaining: 32,
+			timeRemaining: minutes,
 			bots:          Resources{ore: 1},
 		}
 		total *= blueprint.maxGeodes(startState)
@@ -280,6 +308,14 @@ func part2(blueprints []*Blueprint) int {
 }
 
 func run() error {
+	if err := params.Parse(); err != nil {
+		return fmt.Errorf("failed to parse parameters: %v", err)
+	}
+	if err := metrics.Start(); err != nil {
+		return err
+	}
+	defer metrics.Finish()
+
 	input, err := os.Open("input.txt")
 	if err != nil {
 		return fmt.Errorf("failed to open input file: %v", err)
@@ -293,9 +329,9 @@ func run() error {
 	}
 	fmt.Println("blueprints:", blueprints)
 
-	fmt.Println("part 1:", part1(blueprints))
+	fmt.Println("part 1:", part1(blueprints, *part1Minutes))
 
-	fmt.Println("part 2:", part2(blueprints))
+	fmt.Println("part 2:", part2(blueprints, *part2Minutes, *part2Blueprints))
 	return nil
 }
 
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// MoveRule describes one move in a game: its name, the letters standing for
+// it in each column of the guide, and what it scores when played
+type MoveRule struct {
+	Name  string `json:"name"`
+	Their string `json:"their"`
+	Your  string `json:"your"`
+	Score int    `json:"score"`
+}
+
+// Game is a cyclic game with an odd number of moves. Each move beats the
+// half of the other moves just before it in the cycle, and loses to the half
+// just after it, which for three moves is Rock, Paper, Scissors.
+type Game struct {
+	Moves []MoveRule `json:"moves"`
+	// Results scores each result, keyed by "loss", "draw" and "win"
+	Results map[string]int `json:"results"`
+	// Outcomes are the letters standing for each result when the second
+	// column says how the round should end, as in part 2
+	Outcomes map[string]string `json:"outcomes"`
+}
+
+var resultNames = map[Result]string{
+	Loss: "loss",
+	Draw: "draw",
+	Win:  "win",
+}
+
+// Classic is the game from the puzzle, which Part1 and Part2 interpret guides
+// for
+var Classic = Game{
+	Moves: []MoveRule{
+		{"Rock", "A", "X", Rock.score()},
+		{"Paper", "B", "Y", Paper.score()},
+		{"Scissors", "C", "Z", Scissors.score()},
+	},
+	Results:  map[string]int{"loss": Loss.score(), "draw": Draw.score(), "win": Win.score()},
+	Outcomes: map[string]string{"loss": "X", "draw": "Y", "win": "Z"},
+}
+
+func loadGame(path string) (Game, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Game{}, fmt.Errorf("failed to read rules: %v", err)
+	}
+
+	var g Game
+	if err := json.Unmarshal(data, &g); err != nil {
+		return Game{}, fmt.Errorf("failed to parse rules: %v", err)
+	}
+
+	return g, g.validate()
+}
+
+func (g Game) validate() error {
+	if len(g.Moves) < 3 || len(g.Moves)%2 == 0 {
+		return fmt.Errorf("a cyclic game needs an odd number of at least 3 moves, not %d", len(g.Moves))
+	}
+
+	theirs, yours := map[string]bool{}, map[string]bool{}
+	for _, m := range g.Moves {
+		if m.Their == "" || m.Your == "" {
+			return fmt.Errorf("move %s is missing its letters", m.Name)
+		}
+		if theirs[m.Their] || yours[m.Your] {
+			return fmt.Errorf("move %s reuses a letter", m.Name)
+		}
+		theirs[m.Their], yours[m.Your] = true, true
+	}
+
+	outcomes := map[string]bool{}
+	for _, name := range resultNames {
+		if _, exists := g.Results[name]; !exists {
+			return fmt.Errorf("no score for a %s", name)
+		}
+		letter, exists := g.Outcomes[name]
+		if !exists || outcomes[letter] {
+			return fmt.Errorf("no unique letter for a %s", name)
+		}
+		outcomes[letter] = true
+	}
+
+	for name := range g.Results {
+		if !isResultName(name) {
+			return fmt.Errorf("unknown result %s", name)
+		}
+	}
+	return nil
+}
+
+func isResultName(name string) bool {
+	for _, n := range resultNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (g Game) plays(your Move, their Move) Result {
+	n := Move(len(g.Moves))
+	switch difference := (your - their + n) % n; {
+	case difference == 0:
+		return Draw
+	case difference <= n/2:
+		return Win
+	default:
+		return Loss
+	}
+}
+
+func (g Game) score(r Round) int {
+	return g.Moves[r.your].Score + g.Results[resultNames[g.plays(r.your, r.their)]]
+}
+
+func (g Game) scoreGuide(guide []Round) int {
+	total := 0
+	for _, round := range guide {
+		total += g.score(round)
+	}
+
+	return total
+}
+
+func (g Game) theirMove(letter string) (Move, error) {
+	for i, m := range g.Moves {
+		if m.Their == letter {
+			return Move(i), nil
+		}
+	}
+	return Rock, fmt.Errorf("their invalid move %s", letter)
+}
+
+// GameMoves interprets the second column as your move, as in part 1
+type GameMoves struct {
+	game Game
+}
+
+func (i GameMoves) Interpret(your string, _ Move) (Move, error) {
+	for j, m := range i.game.Moves {
+		if m.Your == your {
+			return Move(j), nil
+		}
+	}
+	return Rock, fmt.Errorf("%s is not one of the moves", your)
+}
+
+// GameOutcomes interprets the second column as how the round should end, as
+// in part 2. With more than three moves there's a choice of moves to win or
+// lose with: this picks the neighbouring one, as part 2 does.
+type GameOutcomes struct {
+	game Game
+}
+
+func (i GameOutcomes) Interpret(outcome string, their Move) (Move, error) {
+	n := Move(len(i.game.Moves))
+	switch outcome {
+	case i.game.Outcomes["loss"]:
+		return (their + n - 1) % n, nil
+	case i.game.Outcomes["draw"]:
+		return their, nil
+	case i.game.Outcomes["win"]:
+		return i.game.beats(their), nil
+	default:
+		return Rock, fmt.Errorf("%v is not one of the outcomes", outcome)
+	}
+}
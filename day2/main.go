@@ -2,12 +2,17 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"io"
 	"os"
 	"strings"
+
+	"github.com/WJBarnes456/aoc-2022/params"
 )
 
+var rules = flag.String("rules", "", "JSON file describing a cyclic game to play instead of Rock, Paper, Scissors")
+
 type Move int
 
 const (
@@ -42,17 +47,7 @@ func (r Result) score() int {
 }
 
 func (your Move) plays(their Move) Result {
-	// This works because of the order: you win to the one behind, and lose to the one in front
-	switch (your - their + 3) % 3 {
-	case 0:
-		return Draw
-	case 1:
-		return Win
-	case 2:
-		return Loss
-	default:
-		panic("A difference of values mod 3 was not in the range 0-2 when scoring")
-	}
+	return Classic.plays(your, their)
 }
 
 func (r Round) score() int {
@@ -78,23 +73,23 @@ func readGuide(r io.Reader) ([][]string, error) {
 }
 
 func interpretGuide(guide [][]string, i Interpreter) ([]Round, error) {
+	return Classic.interpretGuide(guide, i)
+}
+
+func (g Game) interpretGuide(guide [][]string, i Interpreter) ([]Round, error) {
 	game := make([]Round, 0)
 
 	for _, vals := range guide {
+		if len(vals) < 2 {
+			return game, fmt.Errorf("line with fewer than 2 values")
+		}
+
 		theirStr := vals[0]
 		yourStr := vals[1]
 
-		var their Move
-
-		switch theirStr {
-		case "A":
-			their = Rock
-		case "B":
-			their = Paper
-		case "C":
-			their = Scissors
-		default:
-			return game, fmt.Errorf("their invalid move %s", theirStr)
+		their, err := g.theirMove(theirStr)
+		if err != nil {
+			return game, err
 		}
 
 		your, err := i.Interpret(yourStr, their)
@@ -154,6 +149,22 @@ func scoreGuide(guide []Round) int {
 }
 
 func main() {
+	if err := params.Parse(); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to parse parameters:", err)
+		os.Exit(1)
+	}
+
+	game := Classic
+	var part1, part2 Interpreter = Part1, Part2
+	if *rules != "" {
+		loaded, err := loadGame(*rules)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to load rules:", err)
+			os.Exit(1)
+		}
+		game, part1, part2 = loaded, GameMoves{loaded}, GameOutcomes{loaded}
+	}
+
 	input, err := readGuide(os.Stdin)
 
 	if err != nil {
@@ -161,21 +172,63 @@ func main() {
 		os.Exit(1)
 	}
 
-	part1Guide, err := interpretGuide(input, Part1)
+	if *plan {
+		their := make([]Move, 0, len(input))
+		for _, vals := range input {
+			move, err := game.theirMove(vals[0])
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "failed to read guide:", err)
+				os.Exit(1)
+			}
+			their = append(their, move)
+		}
+
+		score, yours, err := game.plan(their, Constraints{*lose, *maxEach, *noRepeat})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to plan:", err)
+			os.Exit(1)
+		}
+
+		fmt.Println("Best score:", score)
+		game.writePlan(os.Stdout, their, yours)
+		return
+	}
+
+	part1Guide, err := game.interpretGuide(input, part1)
 
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "failed to interpret guide for part 1:", err)
 		os.Exit(1)
 	}
 
-	fmt.Println("Part 1:", scoreGuide(part1Guide))
-
-	part2Guide, err := interpretGuide(input, Part2)
+	part2Guide, err := game.interpretGuide(input, part2)
 
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "failed to interpret guide for part 2", err)
 		os.Exit(1)
 	}
 
-	fmt.Println("Part 2:", scoreGuide(part2Guide))
+	if *tournament {
+		contenders := standardContenders(game)
+		if len(input) > 0 {
+			their, part1Moves, part2Moves := []Move{}, []Move{}, []Move{}
+			for i := range input {
+				their = append(their, part1Guide[i].their)
+				part1Moves = append(part1Moves, part1Guide[i].your)
+				part2Moves = append(part2Moves, part2Guide[i].your)
+			}
+			contenders = append(contenders,
+				guideContender("elves' moves", their),
+				guideContender("guide as part 1", part1Moves),
+				guideContender("guide as part 2", part2Moves),
+			)
+		}
+
+		fmt.Printf("Tournament of %d rounds per match, with seed %d:\n", *rounds, *seed)
+		writeLeaderboard(os.Stdout, playTournament(game, contenders, *rounds, *seed))
+		return
+	}
+
+	fmt.Println("Part 1:", game.scoreGuide(part1Guide))
+	fmt.Println("Part 2:", game.scoreGuide(part2Guide))
 }
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/WJBarnes456/aoc-2022/difftest"
+)
+
+func TestExample(t *testing.T) {
+	guide := [][]string{{"A", "Y"}, {"B", "X"}, {"C", "Z"}}
+	for _, c := range []struct {
+		interpreter Interpreter
+		expected    int
+	}{
+		{Part1, 15},
+		{Part2, 12},
+		{GameMoves{Classic}, 15},
+		{GameOutcomes{Classic}, 12},
+	} {
+		rounds, err := interpretGuide(guide, c.interpreter)
+		if err != nil {
+			t.Fatalf("failed to interpret guide: %v", err)
+		}
+		if score := Classic.scoreGuide(rounds); score != c.expected {
+			t.Errorf("%T scored %d, expected %d", c.interpreter, score, c.expected)
+		}
+	}
+}
+
+func TestRulesAreBalanced(t *testing.T) {
+	game, err := loadGame("rpsls.json")
+	if err != nil {
+		t.Fatalf("failed to load rules: %v", err)
+	}
+
+	for your := range game.Moves {
+		wins := 0
+		for their := range game.Moves {
+			result := game.plays(Move(your), Move(their))
+			if result == Win {
+				wins++
+			}
+			if result != Draw && game.plays(Move(their), Move(your)) == result {
+				t.Errorf("%s and %s both get a %s", game.Moves[your].Name, game.Moves[their].Name, resultNames[result])
+			}
+		}
+		if wins != 2 {
+			t.Errorf("%s wins against %d moves, expected 2", game.Moves[your].Name, wins)
+		}
+	}
+
+	// Spock vaporizes Rock, and Lizard poisons Spock
+	if game.plays(1, 0) != Win || game.plays(3, 1) != Win {
+		t.Error("moves don't beat what they should")
+	}
+}
+
+func TestTournament(t *testing.T) {
+	contenders := standardContenders(Classic)
+	standings := playTournament(Classic, contenders, 500, 1)
+	if again := playTournament(Classic, contenders, 500, 1); !reflect.DeepEqual(standings, again) {
+		t.Errorf("the same seed gave different standings:\n%v\n%v", standings, again)
+	}
+
+	// the adaptive strategies should make short work of the fixed ones
+	for _, s := range standings[:2] {
+		if s.Name != "pattern matcher" && s.Name != "frequency counter" {
+			t.Errorf("%s came in the top two: %v", s.Name, standings)
+		}
+	}
+}
+
+type planProblem struct {
+	their       []Move
+	constraints Constraints
+}
+
+func (p planProblem) meets(yours []Move) bool {
+	losses, counts := 0, make([]int, len(Classic.Moves))
+	for i, your := range yours {
+		if Classic.plays(your, p.their[i]) == Loss {
+			losses++
+		}
+		counts[your]++
+		if p.constraints.NoRepeat && i > 0 && yours[i-1] == your {
+			return false
+		}
+	}
+	for _, count := range counts {
+		if p.constraints.MaxEach > 0 && count > p.constraints.MaxEach {
+			return false
+		}
+	}
+	return p.constraints.Losses < 0 || losses == p.constraints.Losses
+}
+
+// a naive reference, trying every sequence of moves. -1 means there's no
+// sequence meeting the constraints.
+func bruteForcePlan(p planProblem) int {
+	best := -1
+	yours := make([]Move, len(p.their))
+	var try func(i int)
+	try = func(i int) {
+		if i == len(yours) {
+			if p.meets(yours) {
+				if score := Classic.scoreGuide(roundsFor(p.their, yours)); score > best {
+					best = score
+				}
+			}
+			return
+		}
+		for m := range Classic.Moves {
+			yours[i] = Move(m)
+			try(i + 1)
+		}
+	}
+	try(0)
+	return best
+}
+
+func roundsFor(their []Move, yours []Move) []Round {
+	rounds := make([]Round, len(their))
+	for i := range their {
+		rounds[i] = Round{yours[i], their[i]}
+	}
+	return rounds
+}
+
+// the planner's score, checking its moves really do score that and meet the
+// constraints
+func plannedScore(p planProblem) int {
+	score, yours, err := Classic.plan(p.their, p.constraints)
+	if err != nil {
+		return -1
+	}
+	if !p.meets(yours) || Classic.scoreGuide(roundsFor(p.their, yours)) != score {
+		panic("planned moves don't match the planned score")
+	}
+	return score
+}
+
+func TestPlanMatchesBruteForce(t *testing.T) {
+	h := difftest.Harness[planProblem, int]{}
+	h.Register("plan", plannedScore)
+	h.Register("brute force", bruteForcePlan)
+
+	h.Generate(t, 2, 300, func(rng *rand.Rand) planProblem {
+		their := make([]Move, 1+rng.Intn(7))
+		for i := range their {
+			their[i] = Move(rng.Intn(3))
+		}
+
+		c := Constraints{-1, 0, rng.Intn(2) == 0}
+		if rng.Intn(2) == 0 {
+			c.Losses = rng.Intn(len(their) + 1)
+		}
+		if rng.Intn(2) == 0 {
+			c.MaxEach = 1 + rng.Intn(len(their))
+		}
+		return planProblem{their, c}
+	})
+}
+
+func TestPlanMoves(t *testing.T) {
+	for _, c := range []struct {
+		name        string
+		their       []Move
+		constraints Constraints
+		score       int
+		yours       []Move
+	}{
+		{"beat everything", []Move{Rock, Paper, Scissors}, Constraints{-1, 0, false}, 24, []Move{Paper, Scissors, Rock}},
+		// drawing then winning ties with winning then losing, so this checks
+		// ties are broken the same way every time
+		{"no repeats", []Move{Rock, Rock}, Constraints{-1, 0, true}, 12, []Move{Paper, Rock}},
+		{"lose once", []Move{Rock, Rock, Rock}, Constraints{1, 0, false}, 19, []Move{Paper, Paper, Scissors}},
+	} {
+		for i := 0; i < 20; i++ {
+			score, yours, err := Classic.plan(c.their, c.constraints)
+			if err != nil {
+				t.Fatalf("%s: failed to plan: %v", c.name, err)
+			}
+			if score != c.score || !reflect.DeepEqual(yours, c.yours) {
+				t.Fatalf("%s: planned %v scoring %d, expected %v scoring %d", c.name, yours, score, c.yours, c.score)
+			}
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+var (
+	plan     = flag.Bool("plan", false, "find the highest scoring moves against the guide's first column instead of scoring the guide")
+	lose     = flag.Int("lose", -1, "when planning, lose exactly this many rounds (-1 for any number)")
+	maxEach  = flag.Int("max-each", 0, "when planning, play each move at most this many times (0 for no limit)")
+	noRepeat = flag.Bool("no-repeat", false, "when planning, never play the same move twice in a row")
+)
+
+// Constraints limit which sequences of moves the planner can pick from
+type Constraints struct {
+	// Losses is exactly how many rounds to lose, or -1 for any number
+	Losses int
+	// MaxEach is how many times each move can be played, or 0 for no limit
+	MaxEach int
+	// NoRepeat rules out playing the same move in consecutive rounds
+	NoRepeat bool
+}
+
+// planState is everything about the rounds played so far which the
+// constraints care about. Parts no constraint needs are left at their zero
+// value, so that they don't multiply the number of states.
+type planState struct {
+	losses int
+	last   Move
+	// how many times each move has been played, packed so it can be a key
+	counts string
+}
+
+// sortedStates lists the layer's states in a fixed order. Maps iterate in a
+// random order, so going through them directly would break ties between
+// equally good plans differently from one run to the next.
+func sortedStates(layer map[planState]*planNode) []planState {
+	states := make([]planState, 0, len(layer))
+	for state := range layer {
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		a, b := states[i], states[j]
+		if a.losses != b.losses {
+			return a.losses < b.losses
+		}
+		if a.last != b.last {
+			return a.last < b.last
+		}
+		return a.counts < b.counts
+	})
+	return states
+}
+
+// planNode is the best way found of reaching a state, linked back through
+// the earlier rounds
+type planNode struct {
+	score int
+	move  Move
+	prev  *planNode
+}
+
+func packCounts(counts []uint32) string {
+	packed := make([]byte, 0, 4*len(counts))
+	for _, count := range counts {
+		packed = binary.LittleEndian.AppendUint32(packed, count)
+	}
+	return string(packed)
+}
+
+func unpackCounts(packed string, moves int) []uint32 {
+	counts := make([]uint32, moves)
+	for i := range counts {
+		if len(packed) > 0 {
+			counts[i] = binary.LittleEndian.Uint32([]byte(packed[4*i:]))
+		}
+	}
+	return counts
+}
+
+// plan finds the highest scoring moves to play against their moves under the
+// constraints, going round by round and keeping only the best way of
+// reaching each state. Ties go to whichever plan is found first, going
+// through the states in order and then the moves, so the same guide always
+// gets the same plan.
+//
+// With just the loss and repeat constraints there are at most
+// rounds*moves states per round, so it's quick on a full guide. Limiting how
+// often each move is played means tracking counts for every move, which
+// grows like rounds^(moves-1) and so is only practical for short guides or
+// tight limits.
+func (g Game) plan(their []Move, c Constraints) (int, []Move, error) {
+	moves := len(g.Moves)
+	if c.MaxEach > 0 && c.MaxEach*moves < len(their) {
+		return 0, nil, fmt.Errorf("can't play %d rounds with each of %d moves at most %d times", len(their), moves, c.MaxEach)
+	}
+	if c.Losses > len(their) {
+		return 0, nil, fmt.Errorf("can't lose %d rounds out of %d", c.Losses, len(their))
+	}
+
+	start := planState{last: -1}
+	if c.MaxEach > 0 {
+		start.counts = packCounts(make([]uint32, moves))
+	}
+	layer := map[planState]*planNode{start: nil}
+
+	for i, theirs := range their {
+		remaining := len(their) - i - 1
+		next := map[planState]*planNode{}
+
+		for _, state := range sortedStates(layer) {
+			node := layer[state]
+			score := 0
+			if node != nil {
+				score = node.score
+			}
+
+			for m := 0; m < moves; m++ {
+				your := Move(m)
+				if c.NoRepeat && your == state.last {
+					continue
+				}
+
+				following := planState{last: -1}
+				if c.NoRepeat {
+					following.last = your
+				}
+
+				if c.Losses >= 0 {
+					following.losses = state.losses
+					if g.plays(your, theirs) == Loss {
+						following.losses++
+					}
+					// give up on states which can no longer lose exactly the
+					// right number of rounds
+					if following.losses > c.Losses || following.losses+remaining < c.Losses {
+						continue
+					}
+				}
+
+				if c.MaxEach > 0 {
+					counts := unpackCounts(state.counts, moves)
+					if int(counts[m]) >= c.MaxEach {
+						continue
+					}
+					counts[m]++
+					following.counts = packCounts(counts)
+				}
+
+				newScore := score + g.score(Round{your, theirs})
+				if best, exists := next[following]; !exists || newScore > best.score {
+					next[following] = &planNode{newScore, your, node}
+				}
+			}
+		}
+
+		layer = next
+	}
+
+	var best *planNode
+	for _, state := range sortedStates(layer) {
+		if node := layer[state]; best == nil || node.score > best.score {
+			best = node
+		}
+	}
+
+	if len(their) == 0 {
+		return 0, []Move{}, nil
+	}
+	if best == nil {
+		return 0, nil, fmt.Errorf("no sequence of moves meets the constraints")
+	}
+
+	yours := make([]Move, len(their))
+	for i, node := len(their)-1, best; node != nil; i, node = i-1, node.prev {
+		yours[i] = node.move
+	}
+	return best.score, yours, nil
+}
+
+// writePlan writes the planned moves as a guide with the second column
+// meaning your move, as in part 1
+func (g Game) writePlan(w io.Writer, their []Move, yours []Move) {
+	lines := make([]string, len(their))
+	for i := range their {
+		lines[i] = g.Moves[their[i]].Their + " " + g.Moves[yours[i]].Your
+	}
+	fmt.Fprintln(w, strings.Join(lines, "\n"))
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+var (
+	tournament = flag.Bool("tournament", false, "play a round-robin tournament between strategies instead of scoring the guide")
+	rounds     = flag.Int("rounds", 1000, "rounds per match in the tournament")
+	seed       = flag.Int64("seed", 1, "seed for the tournament's random strategies")
+)
+
+// how many of the opponent's last moves the pattern matcher looks for
+const PATTERN_LENGTH = 3
+
+// Strategy picks moves in a match. Each match gets fresh strategies, and
+// tells them how every round went from their own side.
+type Strategy interface {
+	Play(rng *rand.Rand) Move
+	Observe(Round)
+}
+
+// Contender is a named way of making a strategy for each match
+type Contender struct {
+	Name string
+	New  func(g Game) Strategy
+}
+
+// beats gives the neighbouring move which beats m, as part 2 picks
+func (g Game) beats(m Move) Move {
+	return (m + 1) % Move(len(g.Moves))
+}
+
+type fixedStrategy struct {
+	move Move
+}
+
+func (s fixedStrategy) Play(*rand.Rand) Move { return s.move }
+func (s fixedStrategy) Observe(Round)        {}
+
+type randomStrategy struct {
+	moves int
+}
+
+func (s randomStrategy) Play(rng *rand.Rand) Move { return Move(rng.Intn(s.moves)) }
+func (s randomStrategy) Observe(Round)            {}
+
+// sequenceStrategy replays a list of moves, e.g. from a strategy guide,
+// starting again from the top when it runs out
+type sequenceStrategy struct {
+	moves []Move
+	next  int
+}
+
+func (s *sequenceStrategy) Play(*rand.Rand) Move {
+	move := s.moves[s.next%len(s.moves)]
+	s.next++
+	return move
+}
+func (s *sequenceStrategy) Observe(Round) {}
+
+// frequencyStrategy plays whatever beats the opponent's most common move
+type frequencyStrategy struct {
+	game   Game
+	counts []int
+}
+
+func (s *frequencyStrategy) Play(rng *rand.Rand) Move {
+	return s.game.beats(mostCommon(s.counts, rng))
+}
+
+func (s *frequencyStrategy) Observe(r Round) {
+	s.counts[r.their]++
+}
+
+// mostCommon picks the move with the highest count, breaking ties at random
+// so that it can't be trivially exploited
+func mostCommon(counts []int, rng *rand.Rand) Move {
+	best := []Move{}
+	for m, count := range counts {
+		switch {
+		case len(best) == 0 || count > counts[best[0]]:
+			best = []Move{Move(m)}
+		case count == counts[best[0]]:
+			best = append(best, Move(m))
+		}
+	}
+	return best[rng.Intn(len(best))]
+}
+
+// patternStrategy remembers what the opponent played after each run of
+// PATTERN_LENGTH moves, and plays whatever beats what they played most after
+// their latest run
+type patternStrategy struct {
+	game      Game
+	recent    []Move
+	followers map[string][]int
+}
+
+func patternKey(moves []Move) string {
+	return fmt.Sprint(moves)
+}
+
+func (s *patternStrategy) Play(rng *rand.Rand) Move {
+	counts, exists := s.followers[patternKey(s.recent)]
+	if len(s.recent) < PATTERN_LENGTH || !exists {
+		return Move(rng.Intn(len(s.game.Moves)))
+	}
+	return s.game.beats(mostCommon(counts, rng))
+}
+
+func (s *patternStrategy) Observe(r Round) {
+	if len(s.recent) == PATTERN_LENGTH {
+		key := patternKey(s.recent)
+		if _, exists := s.followers[key]; !exists {
+			s.followers[key] = make([]int, len(s.game.Moves))
+		}
+		s.followers[key][r.their]++
+		s.recent = s.recent[1:]
+	}
+	s.recent = append(s.recent, r.their)
+}
+
+// standardContenders are the built-in strategies: one always playing each
+// move, one playing at random, and the two adaptive ones
+func standardContenders(g Game) []Contender {
+	contenders := []Contender{}
+	for i, m := range g.Moves {
+		move := Move(i)
+		contenders = append(contenders, Contender{"always " + m.Name, func(Game) Strategy {
+			return fixedStrategy{move}
+		}})
+	}
+
+	return append(contenders,
+		Contender{"random", func(g Game) Strategy {
+			return randomStrategy{len(g.Moves)}
+		}},
+		Contender{"frequency counter", func(g Game) Strategy {
+			return &frequencyStrategy{g, make([]int, len(g.Moves))}
+		}},
+		Contender{"pattern matcher", func(g Game) Strategy {
+			return &patternStrategy{g, nil, map[string][]int{}}
+		}},
+	)
+}
+
+// guideContender replays a column of moves from a strategy guide
+func guideContender(name string, moves []Move) Contender {
+	return Contender{name, func(Game) Strategy {
+		return &sequenceStrategy{moves: moves}
+	}}
+}
+
+// Standing is a contender's record over the whole tournament
+type Standing struct {
+	Name   string
+	Score  int
+	Rounds int
+	Wins   int
+	Draws  int
+	Losses int
+}
+
+// playMatch plays two strategies against each other, returning each one's
+// total score
+func playMatch(g Game, a Strategy, b Strategy, rounds int, rng *rand.Rand) (int, int) {
+	scoreA, scoreB := 0, 0
+	for i := 0; i < rounds; i++ {
+		moveA, moveB := a.Play(rng), b.Play(rng)
+		roundA, roundB := Round{moveA, moveB}, Round{moveB, moveA}
+
+		scoreA += g.score(roundA)
+		scoreB += g.score(roundB)
+		a.Observe(roundA)
+		b.Observe(roundB)
+	}
+	return scoreA, scoreB
+}
+
+// playTournament plays every contender against every other. Each match gets
+// its own random source derived from the seed, so the results don't depend
+// on the order the matches are played in.
+func playTournament(g Game, contenders []Contender, rounds int, seed int64) []Standing {
+	standings := make([]Standing, len(contenders))
+	for i, c := range contenders {
+		standings[i].Name = c.Name
+	}
+
+	match := int64(0)
+	for i := range contenders {
+		for j := i + 1; j < len(contenders); j++ {
+			rng := rand.New(rand.NewSource(seed + match))
+			match++
+
+			scoreI, scoreJ := playMatch(g, contenders[i].New(g), contenders[j].New(g), rounds, rng)
+			standings[i].Score += scoreI
+			standings[j].Score += scoreJ
+			standings[i].Rounds += rounds
+			standings[j].Rounds += rounds
+
+			switch {
+			case scoreI > scoreJ:
+				standings[i].Wins++
+				standings[j].Losses++
+			case scoreI < scoreJ:
+				standings[i].Losses++
+				standings[j].Wins++
+			default:
+				standings[i].Draws++
+				standings[j].Draws++
+			}
+		}
+	}
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		return standings[i].Score > standings[j].Score
+	})
+	return standings
+}
+
+func writeLeaderboard(w io.Writer, standings []Standing) {
+	width := 0
+	for _, s := range standings {
+		if len(s.Name) > width {
+			width = len(s.Name)
+		}
+	}
+
+	lines := []string{fmt.Sprintf("    %-*s %10s %8s  %s", width, "strategy", "score", "average", "matches (W/D/L)")}
+	for i, s := range standings {
+		average := 0.0
+		if s.Rounds > 0 {
+			average = float64(s.Score) / float64(s.Rounds)
+		}
+		lines = append(lines, fmt.Sprintf("%2d. %-*s %10d %8.3f  %d/%d/%d", i+1, width, s.Name, s.Score, average, s.Wins, s.Draws, s.Losses))
+	}
+	fmt.Fprintln(w, strings.Join(lines, "\n"))
+}
@@ -2,23 +2,33 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"io"
 	"os"
 	"strconv"
+
+	"github.com/WJBarnes456/aoc-2022/containers"
+	"github.com/WJBarnes456/aoc-2022/params"
+)
+
+const DECRYPTION_KEY = 811589153
+
+var (
+	decryptionKey = flag.Int("key", DECRYPTION_KEY, "decryption key to multiply each value by in part 2")
+	mixRounds     = flag.Int("mixes", 10, "number of times to mix the file in part 2")
+	offsets       = params.Ints("offsets", []int{1000, 2000, 3000}, "positions after 0 to sum for the grove coordinates")
 )
 
-// representing the file as a linked list within an array means we can iterate
-// over the nodes in the order they appear (the array) while altering their
-// positions (the linked list)
-type Node struct {
-	value int
-	prev  *Node
-	next  *Node
+// File is the numbers being mixed. The list holds them in their current
+// order, while elements stays in the original order, which is the order
+// they're moved in.
+type File struct {
+	list     containers.List[int]
+	elements []*containers.Element[int]
 }
 
-func parseInput(r io.Reader) ([]*Node, error) {
-	// first pass: just get the integers
+func parseInput(r io.Reader) ([]int, error) {
 	ints := []int{}
 	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
@@ -29,151 +39,82 @@ func parseInput(r io.Reader) ([]*Node, error) {
 		}
 		ints = append(ints, int(val))
 	}
-
-	// second pass: build the linked list
-	var first, prev *Node
-	nodes := []*Node{}
-	for _, val := range ints {
-		node := &Node{value: val, prev: nil, next: nil}
-
-		if prev == nil {
-			first = node
-		} else {
-			node.prev = prev
-			prev.next = node
-		}
-
-		nodes = append(nodes, node)
-		prev = node
-	}
-
-	// the final value of prev is the last value, so connect them up
-	if first != nil {
-		first.prev = prev
-		prev.next = first
-	}
-
-	return nodes, nil
+	return ints, nil
 }
 
-func clone(nodes []*Node) []*Node {
-	origFirst := nodes[0]
-	newNodes := make([]*Node, 0, len(nodes))
-
-	first := &Node{
-		origFirst.value,
-		nil,
-		nil,
+// newFile puts the values in a list, multiplied by the key
+func newFile(values []int, key int) *File {
+	f := &File{}
+	for _, val := range values {
+		f.elements = append(f.elements, f.list.PushBack(val*key))
 	}
-	newNodes = append(newNodes, first)
-
-	prev := first
-	for cur := origFirst.next; cur != origFirst; cur = cur.next {
-		node := &Node{
-			cur.value,
-			prev,
-			nil,
-		}
-		prev.next = node
-		newNodes = append(newNodes, node)
-		prev = node
-	}
-
-	// connect the head and tail
-	prev.next = first
-	first.prev = prev
-
-	return newNodes
-
+	return f
 }
 
-func Abs(i int) int {
-	if i < 0 {
-		return -i
+// mix moves each number along by its value, in the original order. The list
+// wraps the moves round, never passing an element a full circle.
+func (f *File) mix() {
+	for _, e := range f.elements {
+		f.list.Move(e, e.Value)
 	}
-	return i
 }
 
-func mix(nodes []*Node) {
-	for _, node := range nodes {
-		val := node.value
-
-		// taking the value modulo len(nodes) - 1 means we never pass around the array a full circle
-		// (noting that the elements minus the current value is precisely len(nodes) - 1 in length)
-		shift := Abs(val) % (len(nodes) - 1)
-
-		oldPrev, oldNext := node.prev, node.next
-		newPrev, newNext := node.prev, node.next
-
-		// splice the node out
-		oldPrev.next = oldNext
-		oldNext.prev = oldPrev
-
-		// follow the circular list
-		for shift != 0 {
-			if val < 0 {
-				newPrev, newNext = newPrev.prev, newPrev
-			} else {
-				newPrev, newNext = newNext, newNext.next
-			}
-			shift--
+func (f *File) coordSum(offsets []int) (int, error) {
+	//find 0 in the linked list
+	var cur *containers.Element[int]
+	for _, e := range f.elements {
+		if e.Value == 0 {
+			cur = e
+			break
 		}
-
-		// add the node in the new location
-		node.prev = newPrev
-		newPrev.next = node
-
-		node.next = newNext
-		newNext.prev = node
 	}
-}
+	if cur == nil {
+		return 0, fmt.Errorf("attempted to get coord sum from list with no 0 element")
+	}
 
-func getCoordSum(nodes []*Node) (int, error) {
-	//find 0 in the linked list
-	cur := nodes[0]
-	for cur.value != 0 {
-		cur = cur.next
-		if cur == nodes[0] {
-			return 0, fmt.Errorf("attempted to get coord sum from list with no 0 element")
+	wanted := map[int]int{}
+	furthest := 0
+	for _, offset := range offsets {
+		if offset < 0 {
+			return 0, fmt.Errorf("attempted to get coord sum with negative offset %d", offset)
+		}
+		wanted[offset]++
+		if offset > furthest {
+			furthest = offset
 		}
 	}
 
 	//then traverse the linked list the correct number of elements
-	var first, second, third int
-	for i := 0; i < 3000; i++ {
-		cur = cur.next
-		if i == 999 {
-			first = cur.value
-		} else if i == 1999 {
-			second = cur.value
-		} else if i == 2999 {
-			third = cur.value
-		}
+	// (nb. an offset can be repeated, so count how many times we want each)
+	sum := wanted[0] * cur.Value
+	for i := 1; i <= furthest; i++ {
+		cur = cur.Next()
+		sum += wanted[i] * cur.Value
 	}
-	return first + second + third, nil
+	return sum, nil
 
 }
 
-func part1(nodes []*Node) (int, error) {
-	mix(nodes)
-	return getCoordSum(nodes)
+func part1(values []int, offsets []int) (int, error) {
+	f := newFile(values, 1)
+	f.mix()
+	return f.coordSum(offsets)
 }
 
-const DECRYPTION_KEY = 811589153
-
-func part2(nodes []*Node) (int, error) {
-	for _, node := range nodes {
-		node.value *= DECRYPTION_KEY
-	}
-
-	for i := 0; i < 10; i++ {
-		mix(nodes)
+func part2(values []int, key int, rounds int, offsets []int) (int, error) {
+	f := newFile(values, key)
+	for i := 0; i < rounds; i++ {
+		f.mix()
 	}
 
-	return getCoordSum(nodes)
+	return f.coordSum(offsets)
 }
 
 func run() error {
+	if err := params.Parse(); err != nil {
+		return fmt.Errorf("failed to parse parameters: %v", err)
+	}
+
 	input, err := os.Open("input.txt")
 	if err != nil {
 		return fmt.Errorf("failed to open input.txt: %v", err)
@@ -181,21 +122,18 @@ func run() error {
 
 	defer input.Close()
 
-	nodes, err := parseInput(input)
+	values, err := parseInput(input)
 	if err != nil {
 		return fmt.Errorf("failed to parse input file: %v", err)
 	}
 
-	fmt.Println(nodes)
-	clone := clone(nodes)
-
-	part1, err := part1(nodes)
+	part1, err := part1(values, *offsets)
 	if err != nil {
 		return fmt.Errorf("failed to solve part1: %v", err)
 	}
 	fmt.Println("Part 1:", part1)
 
-	part2, err := part2(clone)
+	part2, err := part2(values, *decryptionKey, *mixRounds, *offsets)
 	if err != nil {
 		return fmt.Errorf("failed to solve part2: %v", err)
 	}
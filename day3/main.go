@@ -3,35 +3,125 @@ package main
 import (
 	"bufio"
 	"errors"
+	"flag"
 	"fmt"
 	"io"
+	"math/bits"
 	"os"
+
+	"github.com/WJBarnes456/aoc-2022/params"
 )
 
-type Rucksack struct {
-	full   []rune
-	first  []rune
-	second []rune
+var (
+	compartments = flag.Int("compartments", 2, "number of equal compartments in each rucksack")
+	groupSize    = flag.Int("group", 3, "number of elves in each group for part 2")
+	allShared    = flag.Bool("all", false, "score every shared item, rather than just the first")
+	tablePath    = flag.String("table", "", "file of items and their priorities to use instead of a-z and A-Z")
+	validateOnly = flag.Bool("validate", false, "only check the rucksacks for items missing from the table")
+)
+
+// ItemSet holds a set of items as the bits of a mask, with bit i set if the
+// item at index i of the Table is present. The first 64 items fit in a single
+// word, which covers the puzzle's 52, and only bigger tables need the rest.
+type ItemSet struct {
+	first uint64
+	// bits 64 and up, a word at a time
+	rest []uint64
+}
+
+func (s *ItemSet) Add(index int) {
+	if index < 64 {
+		s.first |= 1 << index
+		return
+	}
+	s.rest[index/64-1] |= 1 << (index % 64)
+}
+
+func (s ItemSet) Empty() bool {
+	if s.first != 0 {
+		return false
+	}
+	for _, word := range s.rest {
+		if word != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// First gives the lowest index in the set, or -1 if it's empty
+func (s ItemSet) First() int {
+	if s.first != 0 {
+		return bits.TrailingZeros64(s.first)
+	}
+	for i, word := range s.rest {
+		if word != 0 {
+			return (i+1)*64 + bits.TrailingZeros64(word)
+		}
+	}
+	return -1
+}
+
+// Indices lists every index in the set, lowest first
+func (s ItemSet) Indices() []int {
+	indices := []int{}
+	for word := s.first; word != 0; word &= word - 1 {
+		indices = append(indices, bits.TrailingZeros64(word))
+	}
+	for i, word := range s.rest {
+		for ; word != 0; word &= word - 1 {
+			indices = append(indices, (i+1)*64+bits.TrailingZeros64(word))
+		}
+	}
+	return indices
 }
 
-// SharedItem returns the first shared item between the two compartments
-func (r Rucksack) SharedItem() (rune, error) {
-	set := make(map[rune]bool)
+// fill sets every bit, or clears them all
+func (s *ItemSet) fill(all bool) {
+	word := uint64(0)
+	if all {
+		word = ^word
+	}
+	s.first = word
+	for i := range s.rest {
+		s.rest[i] = word
+	}
+}
 
-	for _, item := range r.first {
-		set[item] = true
+// intersect drops anything from the set which isn't in the other one too
+func (s *ItemSet) intersect(other ItemSet) {
+	s.first &= other.first
+	for i := range s.rest {
+		s.rest[i] &= other.rest[i]
 	}
+}
+
+type Rucksack struct {
+	full         []rune
+	compartments [][]rune
+}
 
-	for _, item := range r.second {
-		if set[item] {
-			return item, nil
+// SharedItems returns the items present in every compartment. The set is
+// the table's, so it's only valid until the table's next set is made.
+func (r Rucksack) SharedItems(t *Table) (ItemSet, error) {
+	shared := t.everything()
+	for _, compartment := range r.compartments {
+		if err := t.keep(shared, compartment); err != nil {
+			return ItemSet{}, err
 		}
 	}
 
-	return 0, errors.New("no shared item")
+	if shared.Empty() {
+		return ItemSet{}, errors.New("no shared item")
+	}
+	return *shared, nil
 }
 
-func readRucksacks(r io.Reader) ([]Rucksack, error) {
+func readRucksacks(r io.Reader, numCompartments int) ([]Rucksack, error) {
+	if numCompartments < 1 {
+		return nil, fmt.Errorf("rucksacks need at least one compartment, not %d", numCompartments)
+	}
+
 	scanner := bufio.NewScanner(r)
 
 	rucksacks := make([]Rucksack, 0)
@@ -42,13 +132,15 @@ func readRucksacks(r io.Reader) ([]Rucksack, error) {
 		// although it's not needed here
 		lineRunes := []rune(line)
 
-		if len(lineRunes)%2 != 0 {
-			return nil, fmt.Errorf("line %v cannot be split in 2", line)
+		if len(lineRunes)%numCompartments != 0 {
+			return nil, fmt.Errorf("line %v cannot be split in %d", line, numCompartments)
 		}
 
-		midpoint := len(lineRunes) / 2
-
-		rucksack := Rucksack{lineRunes, lineRunes[:midpoint], lineRunes[midpoint:]}
+		size := len(lineRunes) / numCompartments
+		rucksack := Rucksack{lineRunes, make([][]rune, numCompartments)}
+		for i := range rucksack.compartments {
+			rucksack.compartments[i] = lineRunes[i*size : (i+1)*size]
+		}
 
 		rucksacks = append(rucksacks, rucksack)
 	}
@@ -56,95 +148,91 @@ func readRucksacks(r io.Reader) ([]Rucksack, error) {
 	return rucksacks, nil
 }
 
-func prioritise(item rune) (int, error) {
-	if 'a' <= item && item <= 'z' {
-		return int(item-'a') + 1, nil
-	} else if 'A' <= item && item <= 'Z' {
-		return int(item-'A') + 27, nil
-	} else {
-		return 0, fmt.Errorf("no priority for rune %v", item)
-	}
-}
-
-func part1(rucksacks []Rucksack) (int, error) {
+func part1(rucksacks []Rucksack, t *Table, all bool) (int, error) {
 	total := 0
 	for _, rucksack := range rucksacks {
-		sharedItem, err := rucksack.SharedItem()
-
-		if err != nil {
-			return total, fmt.Errorf("failed to calculate part1: %v", err)
-		}
-
-		score, err := prioritise(sharedItem)
+		shared, err := rucksack.SharedItems(t)
 
 		if err != nil {
 			return total, fmt.Errorf("failed to calculate part1: %v", err)
 		}
 
-		total += score
+		total += t.Score(shared, all)
 	}
 	return total, nil
 }
 
-// This only works for up to 32 rucksacks - a better solution would be to turn
-// the first rucksack into a set, and test membership of each character of
-// that against every subsequent rucksack
-func getSharedItem(rucksacks []Rucksack) (rune, error) {
-	masks := make(map[rune]int)
-
-	// set each bit if it's present in that rucksack
-	for i, rucksack := range rucksacks {
-		for _, c := range rucksack.full {
-			masks[c] = masks[c] | (1 << i)
+// getSharedItems returns the items present in every rucksack of a group. Like
+// SharedItems, the set is only valid until the table's next set is made.
+func getSharedItems(rucksacks []Rucksack, t *Table) (ItemSet, error) {
+	shared := t.everything()
+	for _, rucksack := range rucksacks {
+		if err := t.keep(shared, rucksack.full); err != nil {
+			return ItemSet{}, err
 		}
 	}
 
-	// the shared value is the one present in all rucksacks
-	target_value := 1<<(len(rucksacks)) - 1
-
-	for item, mask := range masks {
-		if mask == target_value {
-			return item, nil
-		}
+	if shared.Empty() {
+		return ItemSet{}, errors.New("no shared item in rucksacks")
 	}
-
-	return 0, errors.New("no shared item in rucksacks")
+	return *shared, nil
 }
 
-func part2(rucksacks []Rucksack) (int, error) {
-	GROUP_SIZE := 3
+func part2(rucksacks []Rucksack, t *Table, groupSize int, all bool) (int, error) {
+	if groupSize < 1 || len(rucksacks)%groupSize != 0 {
+		return 0, fmt.Errorf("%d rucksacks can't be split into groups of %d", len(rucksacks), groupSize)
+	}
 
 	total := 0
-	for i := 0; i < len(rucksacks); i += GROUP_SIZE {
-		group := rucksacks[i : i+GROUP_SIZE]
-		item, err := getSharedItem(group)
+	for i := 0; i < len(rucksacks); i += groupSize {
+		group := rucksacks[i : i+groupSize]
+		shared, err := getSharedItems(group, t)
 
 		if err != nil {
 			return total, fmt.Errorf("failed to get shared item: %v", err)
 		}
 
-		priority, err := prioritise(item)
-
-		if err != nil {
-			return total, fmt.Errorf("failed to prioritise item: %v", err)
-		}
-
-		total += priority
+		total += t.Score(shared, all)
 	}
 
 	return total, nil
 }
 
 func run() error {
-	rucksacks, err := readRucksacks(os.Stdin)
+	if err := params.Parse(); err != nil {
+		return fmt.Errorf("failed to parse parameters: %v", err)
+	}
+
+	table := DefaultTable()
+	if *tablePath != "" {
+		loaded, err := loadTable(*tablePath)
+		if err != nil {
+			return fmt.Errorf("failed to load table: %v", err)
+		}
+		table = loaded
+	}
+
+	rucksacks, err := readRucksacks(os.Stdin, *compartments)
 
 	if err != nil {
 		return fmt.Errorf("failed to read rucksacks: %v", err)
 	}
 
+	if problems := validate(rucksacks, table); len(problems) > 0 {
+		for _, problem := range problems {
+			fmt.Fprintln(os.Stderr, problem)
+		}
+		return fmt.Errorf("%d lines have items missing from the table", len(problems))
+	}
+
+	if *validateOnly {
+		fmt.Println("All items are in the table")
+		return nil
+	}
+
 	//fmt.Fprintln(os.Stderr, "rucksacks:", rucksacks)
 
-	part1, err := part1(rucksacks)
+	part1, err := part1(rucksacks, table, *allShared)
 
 	if err != nil {
 		return fmt.Errorf("failed to do part1: %v", err)
@@ -152,7 +240,7 @@ func run() error {
 
 	fmt.Println("Part 1:", part1)
 
-	part2, err := part2(rucksacks)
+	part2, err := part2(rucksacks, table, *groupSize, *allShared)
 
 	if err != nil {
 		return fmt.Errorf("failed to do part2: %v", err)
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/WJBarnes456/aoc-2022/difftest"
+)
+
+const example = `vJrwpWtwJgWrhcsFMMfFFhFp
+jqHRNqRjqzjGDLGLrsFMfFZSrLrFZsSL
+PmmdzqPrVvPwwTWBwg
+wMqvLMZHhHMvwLHjbvcjnnSBnvTQFn
+ttgJtRGJQctTZtZT
+CrZsJsPPZsGzwwsLwLmpwMDw`
+
+func TestExample(t *testing.T) {
+	rucksacks, err := readRucksacks(strings.NewReader(example), 2)
+	if err != nil {
+		t.Fatalf("failed to read rucksacks: %v", err)
+	}
+
+	if total, err := part1(rucksacks, DefaultTable(), false); err != nil || total != 157 {
+		t.Errorf("part 1 gave %d, %v, expected 157", total, err)
+	}
+	if total, err := part2(rucksacks, DefaultTable(), 3, false); err != nil || total != 70 {
+		t.Errorf("part 2 gave %d, %v, expected 70", total, err)
+	}
+}
+
+const items = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// the priorities of every item shared by the lines, by the bitsets
+func sharedPriorities(lines []string) []int {
+	rucksacks, err := readRucksacks(strings.NewReader(strings.Join(lines, "\n")), 1)
+	if err != nil {
+		panic(err)
+	}
+
+	table := DefaultTable()
+	shared, err := getSharedItems(rucksacks, table)
+	if err != nil {
+		return []int{}
+	}
+	return table.Priorities(shared)
+}
+
+// a naive reference, counting the lines each item appears in with a map
+func referenceSharedPriorities(lines []string) []int {
+	appearances := map[rune]int{}
+	for _, line := range lines {
+		seen := map[rune]bool{}
+		for _, item := range line {
+			if !seen[item] {
+				seen[item] = true
+				appearances[item]++
+			}
+		}
+	}
+
+	priorities := []int{}
+	for item, count := range appearances {
+		if count == len(lines) {
+			priority, _ := DefaultTable().Priority(item)
+			priorities = append(priorities, priority)
+		}
+	}
+	sort.Ints(priorities)
+	return priorities
+}
+
+func TestSharedItemsMatchReference(t *testing.T) {
+	h := difftest.Harness[[]string, []int]{Shrink: difftest.ShrinkSlice[string]}
+	h.Register("bitsets", sharedPriorities)
+	h.Register("reference", referenceSharedPriorities)
+
+	h.Generate(t, 3, 500, func(rng *rand.Rand) []string {
+		// a few common items, so that there's usually something shared
+		common := items[:1+rng.Intn(8)]
+		lines := make([]string, 1+rng.Intn(6))
+		for i := range lines {
+			line := make([]byte, 1+rng.Intn(30))
+			for j := range line {
+				if rng.Intn(2) == 0 {
+					line[j] = common[rng.Intn(len(common))]
+				} else {
+					line[j] = items[rng.Intn(len(items))]
+				}
+			}
+			lines[i] = string(line)
+		}
+		return lines
+	})
+}
+
+func TestCustomTable(t *testing.T) {
+	table, err := loadTable("greek.table")
+	if err != nil {
+		t.Fatalf("failed to load table: %v", err)
+	}
+
+	rucksacks, err := readRucksacks(strings.NewReader("αβγα\nΩωΩz!z"), 2)
+	if err != nil {
+		t.Fatalf("failed to read rucksacks: %v", err)
+	}
+
+	problems := validate(rucksacks, table)
+	if len(problems) != 1 || problems[0].String() != "line 2: unknown items 'z', '!'" {
+		t.Errorf("got problems %v", problems)
+	}
+
+	if total, err := part1(rucksacks[:1], table, false); err != nil || total != 1 {
+		t.Errorf("part 1 gave %d, %v, expected 1", total, err)
+	}
+
+	if _, err := readTable(strings.NewReader("a 1\na 2")); err == nil {
+		t.Error("expected an error for a repeated item")
+	}
+}
+
+func TestLargeTable(t *testing.T) {
+	// more items than fit in a word, with an ASCII item past them all
+	table := NewTable()
+	for i := 0; i < 100; i++ {
+		if err := table.Add(rune(0x100+i), i+1); err != nil {
+			t.Fatalf("failed to add item %d: %v", i, err)
+		}
+	}
+	if err := table.Add('!', 101); err != nil {
+		t.Fatalf("failed to add '!': %v", err)
+	}
+
+	line := string([]rune{0x105, 0x100 + 99, '!', 0x100 + 99, '!', 0x107})
+	rucksacks, err := readRucksacks(strings.NewReader(line), 2)
+	if err != nil {
+		t.Fatalf("failed to read rucksacks: %v", err)
+	}
+
+	if total, err := part1(rucksacks, table, false); err != nil || total != 100 {
+		t.Errorf("part 1 gave %d, %v, expected 100", total, err)
+	}
+	if total, err := part1(rucksacks, table, true); err != nil || total != 201 {
+		t.Errorf("part 1 with every shared item gave %d, %v, expected 201", total, err)
+	}
+}
+
+func TestSharedItemsDontAllocate(t *testing.T) {
+	large := NewTable()
+	for i := 0; i < 200; i++ {
+		large.Add(rune(0x100+i), i+1)
+	}
+	line := string([]rune{0x100, 0x100 + 150, 0x100 + 150, 0x101})
+
+	for _, c := range []struct {
+		name  string
+		table *Table
+		input string
+	}{
+		{"puzzle table", DefaultTable(), example},
+		{"large table", large, strings.Repeat(line+"\n", 6)},
+	} {
+		rucksacks, err := readRucksacks(strings.NewReader(c.input), 2)
+		if err != nil {
+			t.Fatalf("%s: failed to read rucksacks: %v", c.name, err)
+		}
+
+		allocs := testing.AllocsPerRun(10, func() {
+			if _, err := part1(rucksacks, c.table, false); err != nil {
+				t.Fatalf("%s: part 1 failed: %v", c.name, err)
+			}
+			if _, err := part2(rucksacks, c.table, 3, false); err != nil {
+				t.Fatalf("%s: part 2 failed: %v", c.name, err)
+			}
+		})
+		if allocs != 0 {
+			t.Errorf("%s: finding shared items made %v allocations", c.name, allocs)
+		}
+	}
+}
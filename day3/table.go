@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Table gives each item its priority. Items are numbered in the order they
+// were added, which is the bit they take in an ItemSet.
+type Table struct {
+	// ASCII items are looked up in an array, as that's most rucksacks, with
+	// anything else falling back to the map
+	ascii      [utf8.RuneSelf]int
+	others     map[rune]int
+	items      []rune
+	priorities []int
+
+	// the sets used to find shared items, which are reused for every
+	// rucksack so that finding them doesn't allocate
+	shared, scratch ItemSet
+}
+
+func NewTable() *Table {
+	t := &Table{others: map[rune]int{}}
+	for i := range t.ascii {
+		t.ascii[i] = -1
+	}
+	return t
+}
+
+func (t *Table) Add(item rune, priority int) error {
+	if _, exists := t.Index(item); exists {
+		return fmt.Errorf("item %q is already in the table", item)
+	}
+
+	index := len(t.items)
+	if item < utf8.RuneSelf {
+		t.ascii[item] = index
+	} else {
+		t.others[item] = index
+	}
+	t.items = append(t.items, item)
+	t.priorities = append(t.priorities, priority)
+
+	// the sets need another word for every 64 items past the first
+	if index >= 64 && index%64 == 0 {
+		t.shared.rest = append(t.shared.rest, 0)
+		t.scratch.rest = append(t.scratch.rest, 0)
+	}
+	return nil
+}
+
+// Index gives the item's bit in an ItemSet
+func (t *Table) Index(item rune) (int, bool) {
+	if 0 <= item && item < utf8.RuneSelf {
+		index := t.ascii[item]
+		return index, index >= 0
+	}
+	index, exists := t.others[item]
+	return index, exists
+}
+
+func (t *Table) Priority(item rune) (int, error) {
+	index, exists := t.Index(item)
+	if !exists {
+		return 0, fmt.Errorf("no priority for rune %q", item)
+	}
+	return t.priorities[index], nil
+}
+
+// everything gives the table's set for finding shared items, starting out
+// with every item in it, to be narrowed down with keep
+func (t *Table) everything() *ItemSet {
+	t.shared.fill(true)
+	return &t.shared
+}
+
+// keep drops anything from the set which isn't one of the items
+func (t *Table) keep(s *ItemSet, items []rune) error {
+	t.scratch.fill(false)
+	for _, item := range items {
+		index, exists := t.Index(item)
+		if !exists {
+			return fmt.Errorf("no priority for rune %q", item)
+		}
+		t.scratch.Add(index)
+	}
+	s.intersect(t.scratch)
+	return nil
+}
+
+// Score gives the priority of the first shared item in the table, or the
+// total of all of them
+func (t *Table) Score(shared ItemSet, all bool) int {
+	if !all {
+		return t.priorities[shared.First()]
+	}
+
+	total := 0
+	for _, index := range shared.Indices() {
+		total += t.priorities[index]
+	}
+	return total
+}
+
+// Priorities lists the priority of every item in the set, in table order
+func (t *Table) Priorities(s ItemSet) []int {
+	priorities := []int{}
+	for _, index := range s.Indices() {
+		priorities = append(priorities, t.priorities[index])
+	}
+	return priorities
+}
+
+// DefaultTable is the puzzle's: a-z are 1 to 26, and A-Z are 27 to 52
+func DefaultTable() *Table {
+	t := NewTable()
+	for item := 'a'; item <= 'z'; item++ {
+		t.Add(item, int(item-'a')+1)
+	}
+	for item := 'A'; item <= 'Z'; item++ {
+		t.Add(item, int(item-'A')+27)
+	}
+	return t
+}
+
+// readTable reads a table with an item and its priority on each line, like
+// "α 1". Blank lines and lines starting with # are skipped.
+func readTable(r io.Reader) (*Table, error) {
+	t := NewTable()
+	scanner := bufio.NewScanner(r)
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 || utf8.RuneCountInString(fields[0]) != 1 {
+			return nil, fmt.Errorf("line %d: expected an item and its priority, got %q", lineNumber, line)
+		}
+
+		priority, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid priority: %v", lineNumber, err)
+		}
+
+		item, _ := utf8.DecodeRuneInString(fields[0])
+		if err := t.Add(item, priority); err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(t.items) == 0 {
+		return nil, fmt.Errorf("table has no items")
+	}
+	return t, nil
+}
+
+func loadTable(path string) (*Table, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+	defer file.Close()
+
+	return readTable(file)
+}
+
+// UnknownItems are the items on a line of rucksacks which aren't in the table
+type UnknownItems struct {
+	Line  int
+	Items []rune
+}
+
+func (u UnknownItems) String() string {
+	quoted := make([]string, len(u.Items))
+	for i, item := range u.Items {
+		quoted[i] = strconv.QuoteRune(item)
+	}
+	return fmt.Sprintf("line %d: unknown items %s", u.Line, strings.Join(quoted, ", "))
+}
+
+// validate finds every item in the rucksacks missing from the table, by line
+// (counting from 1)
+func validate(rucksacks []Rucksack, t *Table) []UnknownItems {
+	problems := []UnknownItems{}
+	for i, rucksack := range rucksacks {
+		var unknown []rune
+		for _, item := range rucksack.full {
+			if _, exists := t.Index(item); !exists && !containsRune(unknown, item) {
+				unknown = append(unknown, item)
+			}
+		}
+
+		if len(unknown) > 0 {
+			problems = append(problems, UnknownItems{i + 1, unknown})
+		}
+	}
+	return problems
+}
+
+func containsRune(items []rune, item rune) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
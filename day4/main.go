@@ -2,26 +2,35 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"io"
 	"os"
 	"strconv"
 	"strings"
 
+	"github.com/WJBarnes456/aoc-2022/params"
 	"golang.org/x/exp/constraints"
 )
 
+var (
+	analyse = flag.Bool("analyse", false, "analyse the coverage of every elf's sections instead of solving the parts")
+	graph   = flag.Bool("graph", false, "print which elves' sections overlap as a Graphviz graph instead of solving the parts")
+	from    = flag.Int("from", 1, "first section ID to look for uncovered sections from")
+	to      = flag.Int("to", 99, "last section ID to look for uncovered sections up to")
+
+	groupSize = flag.Int("group", 2, "number of elves on each line, or 0 for any number")
+	pairwise  = flag.Bool("pairwise", false, "also print the total overlap between each pair of elves in a group")
+)
+
 type Section struct {
 	start int
 	end   int
 }
 
-type Pair[T, U any] struct {
-	First  T
-	Second U
-}
-
-type Assignment Pair[Section, Section]
+// Assignment is the group of elves on one line, which is a pair unless -group
+// says otherwise
+type Assignment []Section
 
 //Min gets the smaller of any two comparable types
 //
@@ -35,6 +44,13 @@ func min[T constraints.Ordered](a, b T) T {
 	return b
 }
 
+func max[T constraints.Ordered](a, b T) T {
+	if a > b {
+		return a
+	}
+	return b
+}
+
 // Returns the overlap between two sections
 // If the pointer is nil, there is no overlap
 func (my Section) overlap(your Section) *Section {
@@ -79,10 +95,17 @@ func parseSection(s string) (Section, error) {
 		return Section{}, fmt.Errorf("failed to parse end")
 	}
 
+	// the sweep relies on every section covering at least its start
+	if start > end {
+		return Section{}, fmt.Errorf("section %s starts after it ends", s)
+	}
+
 	return Section{int(start), int(end)}, nil
 }
 
-func readAssignments(r io.Reader) ([]Assignment, error) {
+// readAssignments reads groups of groupSize comma-separated sections per
+// line, or any number if groupSize is 0
+func readAssignments(r io.Reader, groupSize int) ([]Assignment, error) {
 	scanner := bufio.NewScanner(r)
 
 	assignments := make([]Assignment, 0)
@@ -91,63 +114,128 @@ func readAssignments(r io.Reader) ([]Assignment, error) {
 
 		sectionsStr := strings.Split(line, ",")
 
-		if len(sectionsStr) != 2 {
-			return nil, fmt.Errorf("line did not have two comma-separated parts")
+		if groupSize > 0 && len(sectionsStr) != groupSize {
+			return nil, fmt.Errorf("line did not have %d comma-separated parts", groupSize)
 		}
 
-		first, err := parseSection(sectionsStr[0])
+		assignment := make(Assignment, len(sectionsStr))
+		for i, sectionStr := range sectionsStr {
+			section, err := parseSection(sectionStr)
+
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse section %d: %v", i+1, err)
+			}
 
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse first section: %v", err)
+			assignment[i] = section
 		}
 
-		second, err := parseSection(sectionsStr[1])
+		assignments = append(assignments, assignment)
+	}
 
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse second section: %v", err)
+	return assignments, nil
+}
+
+// anyContains says whether any elf in the group has a section fully
+// containing another's
+func (a Assignment) anyContains() bool {
+	for i, first := range a {
+		for _, second := range a[i+1:] {
+			overlap := first.overlap(second)
+
+			if overlap == nil {
+				continue
+			}
+
+			if (*overlap) == first || (*overlap) == second {
+				return true
+			}
 		}
+	}
+	return false
+}
 
-		assignments = append(assignments, Assignment{first, second})
+// common returns the sections shared by every elf in the group
+// If the pointer is nil, there are none
+func (a Assignment) common() *Section {
+	if len(a) == 0 {
+		return nil
 	}
 
-	return assignments, nil
+	shared := a[0]
+	for _, section := range a[1:] {
+		shared = Section{max(shared.start, section.start), min(shared.end, section.end)}
+	}
+
+	if shared.start > shared.end {
+		return nil
+	}
+	return &shared
 }
 
-func part1(assignments []Assignment) int {
+// pairwiseOverlap is the total number of sections each pair of elves in the
+// group share
+func (a Assignment) pairwiseOverlap() int {
 	total := 0
-	for _, assignment := range assignments {
-		overlap := assignment.First.overlap(assignment.Second)
-
-		if overlap == nil {
-			continue
+	for i, first := range a {
+		for _, second := range a[i+1:] {
+			if overlap := first.overlap(second); overlap != nil {
+				total += overlap.end - overlap.start + 1
+			}
 		}
+	}
+	return total
+}
 
-		if (*overlap) == assignment.First || (*overlap) == assignment.Second {
+func part1(assignments []Assignment) int {
+	total := 0
+	for _, assignment := range assignments {
+		if assignment.anyContains() {
 			total += 1
 		}
 	}
 	return total
 }
 
+// for pairs, this is whether they overlap at all
 func part2(assignments []Assignment) int {
 	total := 0
 	for _, assignment := range assignments {
-		overlap := assignment.First.overlap(assignment.Second)
-
-		if overlap != nil {
+		if assignment.common() != nil {
 			total += 1
 		}
 	}
 	return total
 }
 
+func totalPairwiseOverlap(assignments []Assignment) int {
+	total := 0
+	for _, assignment := range assignments {
+		total += assignment.pairwiseOverlap()
+	}
+	return total
+}
+
 func run() error {
-	assignments, err := readAssignments(os.Stdin)
+	if err := params.Parse(); err != nil {
+		return fmt.Errorf("failed to parse parameters: %v", err)
+	}
+
+	assignments, err := readAssignments(os.Stdin, *groupSize)
 
 	if err != nil {
 		return fmt.Errorf("failed to read assignments: %v", err)
 	}
 
+	if *analyse {
+		writeAnalysis(os.Stdout, getElves(assignments), *from, *to)
+		return nil
+	}
+
+	if *graph {
+		writeGraph(os.Stdout, getElves(assignments))
+		return nil
+	}
+
 	part1 := part1(assignments)
 
 	fmt.Println("Part 1:", part1)
@@ -156,6 +244,10 @@ func run() error {
 
 	fmt.Println("Part 2:", part2)
 
+	if *pairwise {
+		fmt.Println("Pairwise overlap:", totalPairwiseOverlap(assignments))
+	}
+
 	return nil
 }
 
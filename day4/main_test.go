@@ -0,0 +1,129 @@
+package main
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/WJBarnes456/aoc-2022/difftest"
+)
+
+func TestGroups(t *testing.T) {
+	for _, c := range []struct {
+		input     string
+		groupSize int
+		part1     int
+		part2     int
+		pairwise  int
+	}{
+		{"2-4,6-8\n2-3,4-5\n5-7,7-9\n2-8,3-7\n6-6,4-6\n2-6,4-8", 2, 2, 4, 10},
+		{"2-4,3-5,4-9\n1-2,3-4,2-3\n1-9,2-3,5-5,8-8", 0, 1, 1, 11},
+	} {
+		assignments, err := readAssignments(strings.NewReader(c.input), c.groupSize)
+		if err != nil {
+			t.Fatalf("failed to read assignments: %v", err)
+		}
+
+		if got := part1(assignments); got != c.part1 {
+			t.Errorf("part 1 of %q gave %d, expected %d", c.input, got, c.part1)
+		}
+		if got := part2(assignments); got != c.part2 {
+			t.Errorf("part 2 of %q gave %d, expected %d", c.input, got, c.part2)
+		}
+		if got := totalPairwiseOverlap(assignments); got != c.pairwise {
+			t.Errorf("pairwise overlap of %q gave %d, expected %d", c.input, got, c.pairwise)
+		}
+	}
+
+	if _, err := readAssignments(strings.NewReader("1-2,3-4,5-6"), 2); err == nil {
+		t.Error("expected an error for a group of three when expecting pairs")
+	}
+}
+
+func TestParseSection(t *testing.T) {
+	if section, err := parseSection("3-3"); err != nil || section != (Section{3, 3}) {
+		t.Errorf("parsing 3-3 gave %v, %v", section, err)
+	}
+
+	for _, bad := range []string{"5-3", "3", "a-3", "3-b"} {
+		if _, err := parseSection(bad); err == nil {
+			t.Errorf("expected an error parsing %q", bad)
+		}
+	}
+	if _, err := readAssignments(strings.NewReader("5-3,1-2"), 2); err == nil {
+		t.Error("expected an error for a reversed section")
+	}
+}
+
+// the range of section IDs the analyses are checked over
+const FROM, TO = 1, 20
+
+type analysis struct {
+	maxCoverage int
+	uncovered   []Section
+	redundant   []Elf
+	graph       [][]int
+}
+
+func sweep(elves []Elf) analysis {
+	spans := coverage(elves)
+	count, _ := maxCoverage(spans)
+	return analysis{count, uncovered(spans, FROM, TO), redundant(elves, spans), overlapGraph(elves)}
+}
+
+// a naive reference, counting the elves covering every section ID
+func countEverySection(elves []Elf) analysis {
+	counts := make([]int, TO+2)
+	for _, elf := range elves {
+		for id := elf.Section.start; id <= elf.Section.end; id++ {
+			counts[id]++
+		}
+	}
+
+	a := analysis{uncovered: []Section{}, redundant: []Elf{}, graph: make([][]int, len(elves))}
+	for id := FROM; id <= TO; id++ {
+		if counts[id] > a.maxCoverage {
+			a.maxCoverage = counts[id]
+		}
+		if counts[id] == 0 {
+			if n := len(a.uncovered); n > 0 && a.uncovered[n-1].end == id-1 {
+				a.uncovered[n-1].end = id
+			} else {
+				a.uncovered = append(a.uncovered, Section{id, id})
+			}
+		}
+	}
+
+	for i, elf := range elves {
+		covered := true
+		for id := elf.Section.start; id <= elf.Section.end; id++ {
+			covered = covered && counts[id] >= 2
+		}
+		if covered {
+			a.redundant = append(a.redundant, elf)
+		}
+
+		for j, other := range elves {
+			if i != j && elf.Section.overlap(other.Section) != nil {
+				a.graph[i] = append(a.graph[i], j)
+			}
+		}
+	}
+	return a
+}
+
+func TestSweepMatchesReference(t *testing.T) {
+	h := difftest.Harness[[]Elf, analysis]{Shrink: difftest.ShrinkSlice[Elf]}
+	h.Register("sweep", sweep)
+	h.Register("reference", countEverySection)
+
+	h.Generate(t, 4, 500, func(rng *rand.Rand) []Elf {
+		elves := make([]Elf, 1+rng.Intn(10))
+		for i := range elves {
+			start := FROM + rng.Intn(TO-FROM+1)
+			end := start + rng.Intn(TO-start+1)
+			elves[i] = Elf{Section{start, end}, i/2 + 1, i%2 + 1}
+		}
+		return elves
+	})
+}
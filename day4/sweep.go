@@ -0,0 +1,277 @@
+package main
+
+import (
+	"container/heap"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Elf is one elf's section, along with where it came from in the input
+type Elf struct {
+	Section Section
+	// the line of the input, counting from 1
+	Line int
+	// the position on the line, counting from 1
+	Member int
+}
+
+func (e Elf) String() string {
+	return fmt.Sprintf("%d.%d", e.Line, e.Member)
+}
+
+func getElves(assignments []Assignment) []Elf {
+	elves := make([]Elf, 0, 2*len(assignments))
+	for i, assignment := range assignments {
+		for j, section := range assignment {
+			elves = append(elves, Elf{section, i + 1, j + 1})
+		}
+	}
+	return elves
+}
+
+// Span is a run of section IDs which are all covered by the same number of
+// elves
+type Span struct {
+	Section Section
+	Count   int
+}
+
+type event struct {
+	position int
+	delta    int
+}
+
+// coverage sweeps over the sections from left to right, giving the spans
+// from the first section ID covered to the last, in order. It's O(n log n)
+// for the sort, then linear.
+func coverage(elves []Elf) []Span {
+	events := make([]event, 0, 2*len(elves))
+	for _, elf := range elves {
+		events = append(events, event{elf.Section.start, 1}, event{elf.Section.end + 1, -1})
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].position < events[j].position
+	})
+
+	spans := []Span{}
+	count := 0
+	for i := 0; i < len(events); {
+		// apply every event at this position before starting the next span
+		position := events[i].position
+		for ; i < len(events) && events[i].position == position; i++ {
+			count += events[i].delta
+		}
+
+		if i < len(events) {
+			spans = append(spans, Span{Section{position, events[i].position - 1}, count})
+		}
+	}
+	return spans
+}
+
+// maxCoverage gives the most elves covering any one section ID, and the
+// first run of IDs covered by that many
+func maxCoverage(spans []Span) (int, Section) {
+	best := Span{}
+	for _, span := range spans {
+		if span.Count > best.Count {
+			best = span
+		}
+	}
+	return best.Count, best.Section
+}
+
+// uncovered gives the runs of section IDs from from to to (inclusive) which
+// no elf covers
+func uncovered(spans []Span, from int, to int) []Section {
+	gaps := []Section{}
+	if from > to {
+		return gaps
+	}
+
+	next := from
+	for _, span := range spans {
+		if span.Count == 0 {
+			continue
+		}
+		if span.Section.start > next {
+			gaps = append(gaps, Section{next, min(span.Section.start-1, to)})
+		}
+		if span.Section.end+1 > next {
+			next = span.Section.end + 1
+		}
+		if next > to {
+			return gaps
+		}
+	}
+	return append(gaps, Section{next, to})
+}
+
+// minTable answers the smallest count over any range of spans in O(1), after
+// O(n log n) building: level k holds the minimum of each run of 2^k spans
+type minTable [][]int
+
+func newMinTable(spans []Span) minTable {
+	level := make([]int, len(spans))
+	for i, span := range spans {
+		level[i] = span.Count
+	}
+
+	table := minTable{level}
+	for width := 2; width <= len(spans); width *= 2 {
+		previous := table[len(table)-1]
+		level := make([]int, len(spans)-width+1)
+		for i := range level {
+			level[i] = min(previous[i], previous[i+width/2])
+		}
+		table = append(table, level)
+	}
+	return table
+}
+
+// between gives the smallest count from span i to span j inclusive
+func (t minTable) between(i int, j int) int {
+	k, width := 0, 1
+	for width*2 <= j-i+1 {
+		k, width = k+1, width*2
+	}
+	return min(t[k][i], t[k][j-width+1])
+}
+
+// redundant gives the elves whose whole section is covered by other elves,
+// i.e. every ID of it is covered at least twice
+func redundant(elves []Elf, spans []Span) []Elf {
+	table := newMinTable(spans)
+
+	// the span holding a section ID, which spans always cover for an elf's
+	// own IDs
+	spanOf := func(id int) int {
+		return sort.Search(len(spans), func(i int) bool {
+			return spans[i].Section.end >= id
+		})
+	}
+
+	result := []Elf{}
+	for _, elf := range elves {
+		if elf.Section.start > elf.Section.end {
+			continue
+		}
+		if table.between(spanOf(elf.Section.start), spanOf(elf.Section.end)) >= 2 {
+			result = append(result, elf)
+		}
+	}
+	return result
+}
+
+// endHeap holds the indices of the elves whose sections the sweep is inside,
+// earliest ending first
+type endHeap struct {
+	elves   []Elf
+	indices []int
+}
+
+func (h endHeap) Len() int { return len(h.indices) }
+func (h endHeap) Less(i, j int) bool {
+	return h.elves[h.indices[i]].Section.end < h.elves[h.indices[j]].Section.end
+}
+func (h endHeap) Swap(i, j int) { h.indices[i], h.indices[j] = h.indices[j], h.indices[i] }
+func (h *endHeap) Push(x any)   { h.indices = append(h.indices, x.(int)) }
+func (h *endHeap) Pop() any {
+	last := h.indices[len(h.indices)-1]
+	h.indices = h.indices[:len(h.indices)-1]
+	return last
+}
+
+// overlapGraph gives, for each elf, the indices of the other elves whose
+// sections overlap it. Sweeping through the elves by start, each one overlaps
+// exactly those that haven't ended yet, so this is O(n log n + edges).
+func overlapGraph(elves []Elf) [][]int {
+	order := make([]int, len(elves))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return elves[order[i]].Section.start < elves[order[j]].Section.start
+	})
+
+	graph := make([][]int, len(elves))
+	active := &endHeap{elves: elves}
+	for _, i := range order {
+		for active.Len() > 0 && elves[active.indices[0]].Section.end < elves[i].Section.start {
+			heap.Pop(active)
+		}
+
+		for _, j := range active.indices {
+			graph[i] = append(graph[i], j)
+			graph[j] = append(graph[j], i)
+		}
+		heap.Push(active, i)
+	}
+
+	for _, neighbours := range graph {
+		sort.Ints(neighbours)
+	}
+	return graph
+}
+
+func elfNames(elves []Elf) string {
+	if len(elves) == 0 {
+		return "none"
+	}
+
+	names := make([]string, len(elves))
+	for i, elf := range elves {
+		names[i] = elf.String()
+	}
+	return strings.Join(names, ", ")
+}
+
+func sectionNames(sections []Section) string {
+	if len(sections) == 0 {
+		return "none"
+	}
+
+	names := make([]string, len(sections))
+	for i, s := range sections {
+		names[i] = fmt.Sprintf("%d-%d", s.start, s.end)
+	}
+	return strings.Join(names, ", ")
+}
+
+// writeAnalysis writes the coverage of the whole input. Elves are named by
+// line and position, so 3.2 is the second elf on the third line.
+func writeAnalysis(w io.Writer, elves []Elf, from int, to int) {
+	spans := coverage(elves)
+	if len(spans) == 0 {
+		fmt.Fprintln(w, "No sections assigned")
+		return
+	}
+
+	count, where := maxCoverage(spans)
+	fmt.Fprintf(w, "Most elves covering a section: %d, first at %d-%d\n", count, where.start, where.end)
+	fmt.Fprintf(w, "Uncovered sections from %d to %d: %s\n", from, to, sectionNames(uncovered(spans, from, to)))
+	fmt.Fprintf(w, "Elves covered by others: %s\n", elfNames(redundant(elves, spans)))
+
+	edges := 0
+	for _, neighbours := range overlapGraph(elves) {
+		edges += len(neighbours)
+	}
+	fmt.Fprintf(w, "Overlapping pairs of elves: %d\n", edges/2)
+}
+
+// writeGraph writes the overlap graph in Graphviz's DOT format
+func writeGraph(w io.Writer, elves []Elf) {
+	lines := []string{"graph overlaps {"}
+	for i, neighbours := range overlapGraph(elves) {
+		lines = append(lines, fmt.Sprintf("  %q;", elves[i].String()))
+		for _, j := range neighbours {
+			if j > i {
+				lines = append(lines, fmt.Sprintf("  %q -- %q;", elves[i].String(), elves[j].String()))
+			}
+		}
+	}
+	lines = append(lines, "}")
+	fmt.Fprintln(w, strings.Join(lines, "\n"))
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/WJBarnes456/aoc-2022/containers"
+)
+
+// Crane is a model of crane, which decides how a move shifts the crates
+type Crane interface {
+	Name() string
+	// Apply carries out the move on the stacks, in place
+	Apply(crates []containers.Stack[Crate], m Move) error
+}
+
+// CrateMover9000 moves crates one at a time, so a move reverses their order
+type CrateMover9000 struct{}
+
+// CrateMover9001 moves all the crates at once, so a move keeps their order
+type CrateMover9001 struct{}
+
+// LimitedCrane lifts at most Capacity crates at once, keeping the order of
+// each lift. A capacity of 1 is a CrateMover 9000.
+type LimitedCrane struct {
+	Capacity int
+}
+
+func (CrateMover9000) Name() string { return "CrateMover 9000" }
+func (CrateMover9001) Name() string { return "CrateMover 9001" }
+func (c LimitedCrane) Name() string {
+	return fmt.Sprintf("crane lifting %d crates", c.Capacity)
+}
+
+func (CrateMover9000) Apply(crates []containers.Stack[Crate], m Move) error {
+	return LimitedCrane{1}.Apply(crates, m)
+}
+
+func (CrateMover9001) Apply(crates []containers.Stack[Crate], m Move) error {
+	if err := checkMove(crates, m); err != nil {
+		return err
+	}
+	lift(crates, m.source, m.destination, m.count)
+	return nil
+}
+
+func (c LimitedCrane) Apply(crates []containers.Stack[Crate], m Move) error {
+	if c.Capacity < 1 {
+		return fmt.Errorf("a crane must lift at least 1 crate, not %d", c.Capacity)
+	}
+	if err := checkMove(crates, m); err != nil {
+		return err
+	}
+
+	for remaining := m.count; remaining > 0; remaining -= c.Capacity {
+		lift(crates, m.source, m.destination, min(remaining, c.Capacity))
+	}
+	return nil
+}
+
+func min(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func checkMove(crates []containers.Stack[Crate], m Move) error {
+	if m.source < 0 || m.source >= len(crates) || m.destination < 0 || m.destination >= len(crates) {
+		return fmt.Errorf("%v refers to a stack which doesn't exist", m)
+	}
+	if m.count > crates[m.source].Size() {
+		return fmt.Errorf("%v needs more crates than stack %d has", m, m.source+1)
+	}
+	return nil
+}
+
+// lift moves the top count crates from one stack to another in a single
+// block, keeping their order
+func lift(crates []containers.Stack[Crate], source int, destination int, count int) {
+	sourceCrates := crates[source]
+	cutPoint := len(sourceCrates) - count
+
+	// copy what's moving first, as the source and destination can be the
+	// same stack
+	toMove := make([]Crate, count)
+	copy(toMove, sourceCrates[cutPoint:])
+
+	crates[source] = sourceCrates[:cutPoint]
+	crates[destination] = append(crates[destination], toMove...)
+}
+
+// tops gives the crate at the top of each stack, with a space for any empty
+// stack
+func tops(crates []containers.Stack[Crate]) []Crate {
+	out := make([]Crate, 0, len(crates))
+	for _, s := range crates {
+		top, ok := s.TryPeek()
+		if !ok {
+			top = ' '
+		}
+		out = append(out, top)
+	}
+
+	return out
+}
+
+// rearrange applies every move with the crane, returning the top crates
+func rearrange(crane Crane, crates []containers.Stack[Crate], moves []Move) ([]Crate, error) {
+	for i, m := range moves {
+		if err := crane.Apply(crates, m); err != nil {
+			return nil, fmt.Errorf("failed to apply move %d: %v", i+1, err)
+		}
+	}
+
+	return tops(crates), nil
+}
+
+// Trace records the state of the stacks after every move, so a rearrangement
+// can be stepped backwards and forwards through. Applying a move after
+// undoing some replaces the ones undone, like an editor.
+type Trace struct {
+	crane Crane
+	// states[i] is the state after the first i moves
+	states   [][]containers.Stack[Crate]
+	moves    []Move
+	position int
+}
+
+func NewTrace(crane Crane, crates []containers.Stack[Crate]) *Trace {
+	return &Trace{crane: crane, states: [][]containers.Stack[Crate]{cloneCrates(crates)}}
+}
+
+func (t *Trace) Apply(m Move) error {
+	next := cloneCrates(t.State())
+	if err := t.crane.Apply(next, m); err != nil {
+		return err
+	}
+
+	t.states = append(t.states[:t.position+1], next)
+	t.moves = append(t.moves[:t.position], m)
+	t.position++
+	return nil
+}
+
+// Undo steps back a move, returning false if there's nothing to undo
+func (t *Trace) Undo() bool {
+	if t.position == 0 {
+		return false
+	}
+	t.position--
+	return true
+}
+
+// Redo steps forward a move, returning false if there's nothing to redo
+func (t *Trace) Redo() bool {
+	if t.position == len(t.moves) {
+		return false
+	}
+	t.position++
+	return true
+}
+
+// State is the stacks after the moves up to the current position. It's
+// shared with the trace, so mustn't be changed.
+func (t *Trace) State() []containers.Stack[Crate] {
+	return t.states[t.position]
+}
+
+// Position is how many moves have been applied to reach the current state
+func (t *Trace) Position() int {
+	return t.position
+}
+
+// Moves are all the moves recorded, including any undone
+func (t *Trace) Moves() []Move {
+	return t.moves
+}
+
+// States are the stacks after each recorded move, starting with the initial
+// state
+func (t *Trace) States() [][]containers.Stack[Crate] {
+	return t.states
+}
@@ -2,38 +2,92 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"io"
 	"os"
 	"regexp"
 	"strconv"
+	"strings"
+
+	"github.com/WJBarnes456/aoc-2022/containers"
+	"github.com/WJBarnes456/aoc-2022/params"
 )
 
-type Stack[T any] []T
+var (
+	capacity = flag.Int("capacity", 0, "also rearrange with a crane lifting at most this many crates at once")
+	trace    = flag.Bool("trace", false, "print the stacks after every move")
+	reprint  = flag.Bool("print", false, "print the input back out in the puzzle's format instead of solving it")
 
-type Crate rune
+	planTops   = flag.String("plan-tops", "", "plan the fewest moves to get these crates on top of the stacks (* for any crate, . for an empty stack)")
+	planTarget = flag.String("plan-target", "", "plan the fewest moves to reach the stacks drawn in this file")
+	craneName  = flag.String("crane", "9001", "crane to plan with: 9000, 9001, or limited (lifting -capacity crates)")
+	maxStates  = flag.Int("max-states", 1000000, "most arrangements to search through when planning")
+)
 
-func (s *Stack[T]) Size() int {
-	return len(*s)
+func craneNamed(name string, capacity int) (Crane, error) {
+	switch name {
+	case "9000":
+		return CrateMover9000{}, nil
+	case "9001":
+		return CrateMover9001{}, nil
+	case "limited":
+		if capacity < 1 {
+			return nil, fmt.Errorf("a limited crane needs a -capacity of at least 1")
+		}
+		return LimitedCrane{capacity}, nil
+	default:
+		return nil, fmt.Errorf("unknown crane %q", name)
+	}
 }
 
-func (s *Stack[T]) Push(val T) {
-	(*s) = append((*s), val)
+func loadTarget(path string) ([]containers.Stack[Crate], error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+	defer file.Close()
+
+	crates, _, err := readInput(file)
+	return crates, err
 }
 
-// this will panic if the stack is empty - fine for this problem
-// but in a general case it's hard to error if you don't have a value of type T to hand!
-func (s *Stack[T]) Pop() T {
-	final := len(*s) - 1
-	val := (*s)[final]
-	(*s) = (*s)[:final]
+// printPlan searches for the fewest moves to the goal, writing them out with
+// the starting stacks, so that the plan is itself a puzzle input
+func printPlan(crates []containers.Stack[Crate]) error {
+	crane, err := craneNamed(*craneName, *capacity)
+	if err != nil {
+		return err
+	}
 
-	return val
+	var goal Goal
+	if *planTarget != "" {
+		target, err := loadTarget(*planTarget)
+		if err != nil {
+			return fmt.Errorf("failed to load target: %v", err)
+		}
+		goal, err = newArrangementGoal(crates, target)
+		if err != nil {
+			return err
+		}
+	} else {
+		goal, err = newTopsGoal(crates, *planTops)
+		if err != nil {
+			return err
+		}
+	}
+
+	moves, err := plan(crane, crates, goal, *maxStates)
+	if err != nil {
+		return fmt.Errorf("failed to plan: %v", err)
+	}
+	return writeInput(os.Stdout, crates, moves)
 }
 
-func (s *Stack[T]) Peek() T {
-	final := len(*s) - 1
-	return (*s)[final]
+type Crate rune
+
+func (c Crate) String() string {
+	return string(c)
 }
 
 type Move struct {
@@ -42,7 +96,11 @@ type Move struct {
 	destination int
 }
 
-func readInput(r io.Reader) ([]Stack[Crate], []Move, error) {
+func (m Move) String() string {
+	return fmt.Sprintf("move %d from %d to %d", m.count, m.source+1, m.destination+1)
+}
+
+func readInput(r io.Reader) ([]containers.Stack[Crate], []Move, error) {
 	scanner := bufio.NewScanner(r)
 
 	crateMatch, err := regexp.Compile(`^(?:(?:\[.\]|   ) ?)+$`)
@@ -51,36 +109,47 @@ func readInput(r io.Reader) ([]Stack[Crate], []Move, error) {
 		return nil, nil, fmt.Errorf("failed to compile crate regex: %v", err)
 	}
 
-	state := make([]Stack[Crate], 0)
-	// parse the stacks of crates
+	// read the whole diagram first, as the stacks are counted from the
+	// numbering at the bottom rather than from the first line, which may be
+	// missing its trailing spaces
+	diagram := make([]string, 0)
 	for scanner.Scan() {
 		// when we see a blank line, we should parse moves instead
 		line := scanner.Text()
 		if line == "" {
 			break
 		}
+		diagram = append(diagram, line)
+	}
 
-		lineRunes := []rune(line)
+	if len(diagram) == 0 {
+		return nil, nil, fmt.Errorf("no stack diagram")
+	}
 
-		// on the first move, we need to initialise state
-		if len(state) == 0 {
-			nCrates := (len(lineRunes) + 1) / 4
-			for i := 0; i < nCrates; i++ {
-				state = append(state, make(Stack[Crate], 0))
-			}
+	footer := strings.Fields(diagram[len(diagram)-1])
+	for i, label := range footer {
+		if label != strconv.Itoa(i+1) {
+			return nil, nil, fmt.Errorf("stack numbering %q is not 1 to %d", diagram[len(diagram)-1], len(footer))
 		}
+	}
 
-		// skip any lines with an invalid format
-		// (in particular the numbering at the bottom)
+	state := make([]containers.Stack[Crate], len(footer))
+	// parse the stacks of crates
+	for _, line := range diagram[:len(diagram)-1] {
 		if !crateMatch.MatchString(line) {
-			continue
+			return nil, nil, fmt.Errorf("invalid line in stack diagram: %q", line)
 		}
 
-		for i := 0; i < len(state); i++ {
+		lineRunes := []rune(line)
+
+		if (len(lineRunes)+1)/4 > len(state) {
+			return nil, nil, fmt.Errorf("line %q has more crates than there are stacks", line)
+		}
+
+		for i := 0; i < len(state) && 4*i+1 < len(lineRunes); i++ {
 			crate := lineRunes[4*i+1]
 
 			if crate != ' ' {
-				fmt.Printf("adding %c to %v\n", crate, state[i])
 				state[i].Push(Crate(crate))
 			}
 		}
@@ -89,7 +158,7 @@ func readInput(r io.Reader) ([]Stack[Crate], []Move, error) {
 	// we built the state by pushing them on in the opposite order, we need to now reverse them
 	// (better this way to avoid lots of memory allocations)
 	for i, stack := range state {
-		newStack := make(Stack[Crate], 0, stack.Size())
+		newStack := make(containers.Stack[Crate], 0, stack.Size())
 
 		for j := stack.Size() - 1; j >= 0; j-- {
 			newStack.Push(stack[j])
@@ -99,7 +168,7 @@ func readInput(r io.Reader) ([]Stack[Crate], []Move, error) {
 	}
 
 	// parse the moves
-	matchMoves, err := regexp.Compile(`move (\d+) from (\d+) to (\d+)`)
+	matchMoves, err := regexp.Compile(`^move (\d+) from (\d+) to (\d+)$`)
 
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to compile regex: %v", err)
@@ -107,7 +176,15 @@ func readInput(r io.Reader) ([]Stack[Crate], []Move, error) {
 
 	moves := make([]Move, 0)
 	for scanner.Scan() {
-		vals := matchMoves.FindStringSubmatch(scanner.Text())
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		vals := matchMoves.FindStringSubmatch(line)
+		if vals == nil {
+			return nil, nil, fmt.Errorf("invalid move %q", line)
+		}
 
 		intVals := make([]int, 0, len(vals))
 
@@ -133,11 +210,11 @@ func readInput(r io.Reader) ([]Stack[Crate], []Move, error) {
 	return state, moves, nil
 }
 
-func cloneCrates(crates []Stack[Crate]) []Stack[Crate] {
-	out := make([]Stack[Crate], 0, len(crates))
+func cloneCrates(crates []containers.Stack[Crate]) []containers.Stack[Crate] {
+	out := make([]containers.Stack[Crate], 0, len(crates))
 
 	for _, stack := range crates {
-		newStack := make(Stack[Crate], stack.Size())
+		newStack := make(containers.Stack[Crate], stack.Size())
 		copy(newStack, stack)
 		out = append(out, newStack)
 	}
@@ -145,63 +222,89 @@ func cloneCrates(crates []Stack[Crate]) []Stack[Crate] {
 	return out
 }
 
-func part1(crates []Stack[Crate], moves []Move) []Crate {
+func part1(crates []containers.Stack[Crate], moves []Move) ([]Crate, error) {
+	return rearrange(CrateMover9000{}, crates, moves)
+}
+
+func part2(crates []containers.Stack[Crate], moves []Move) ([]Crate, error) {
+	return rearrange(CrateMover9001{}, crates, moves)
+}
+
+func printTrace(crane Crane, crates []containers.Stack[Crate], moves []Move) error {
+	t := NewTrace(crane, crates)
+	fmt.Printf("%s, initially:\n", crane.Name())
+	writeDiagram(os.Stdout, t.State())
 	for _, m := range moves {
-		for i := 0; i < m.count; i++ {
-			val := crates[m.source].Pop()
-			crates[m.destination].Push(val)
+		if err := t.Apply(m); err != nil {
+			return fmt.Errorf("failed to apply %v: %v", m, err)
 		}
+		fmt.Printf("\nafter %v:\n", m)
+		writeDiagram(os.Stdout, t.State())
 	}
+	fmt.Println()
+	return nil
+}
 
-	out := make([]Crate, 0, len(crates))
-	for _, s := range crates {
-		out = append(out, s.Peek())
+func run() error {
+	if err := params.Parse(); err != nil {
+		return fmt.Errorf("failed to parse parameters: %v", err)
 	}
 
-	return out
-}
+	crates, moves, err := readInput(os.Stdin)
 
-func part2(crates []Stack[Crate], moves []Move) []Crate {
-	for _, m := range moves {
-		sourceCrates := crates[m.source]
-		cutPoint := len(sourceCrates) - m.count
+	if err != nil {
+		return fmt.Errorf("failed to read input: %v", err)
+	}
 
-		toMove := sourceCrates[cutPoint:]
+	if *reprint {
+		return writeInput(os.Stdout, crates, moves)
+	}
 
-		//fmt.Printf("moving %v from %v to %v\n", toMove, crates[m.source], crates[m.destination])
+	if *planTops != "" || *planTarget != "" {
+		return printPlan(crates)
+	}
 
-		crates[m.source] = sourceCrates[:cutPoint]
+	fmt.Println("Crates: ", crates)
+	fmt.Println("Moves: ", moves)
 
-		crates[m.destination] = append(crates[m.destination], toMove...)
+	cranes := []Crane{CrateMover9000{}, CrateMover9001{}}
+	if *capacity > 0 {
+		cranes = append(cranes, LimitedCrane{*capacity})
+	}
 
-		//fmt.Printf("crates: %v\n", crates)
+	if *trace {
+		for _, crane := range cranes {
+			if err := printTrace(crane, crates, moves); err != nil {
+				return err
+			}
+		}
 	}
 
-	out := make([]Crate, 0, len(crates))
-	for _, s := range crates {
-		out = append(out, s.Peek())
+	part1, err := part1(cloneCrates(crates), moves)
+
+	if err != nil {
+		return fmt.Errorf("failed to do part1: %v", err)
 	}
 
-	return out
-}
+	fmt.Println("Part 1:", string(part1))
 
-func run() error {
-	crates, moves, err := readInput(os.Stdin)
+	part2, err := part2(cloneCrates(crates), moves)
 
 	if err != nil {
-		return fmt.Errorf("failed to read input: %v", err)
+		return fmt.Errorf("failed to do part2: %v", err)
 	}
 
-	fmt.Println("Crates: ", crates)
-	fmt.Println("Moves: ", moves)
+	fmt.Println("Part 2:", string(part2))
 
-	part1 := part1(cloneCrates(crates), moves)
+	if *capacity > 0 {
+		limited, err := rearrange(LimitedCrane{*capacity}, cloneCrates(crates), moves)
 
-	fmt.Println("Part 1:", string(part1))
-
-	part2 := part2(cloneCrates(crates), moves)
+		if err != nil {
+			return fmt.Errorf("failed to rearrange with capacity %d: %v", *capacity, err)
+		}
 
-	fmt.Println("Part 2:", string(part2))
+		fmt.Printf("Capacity %d: %s\n", *capacity, string(limited))
+	}
 
 	return nil
 }
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/WJBarnes456/aoc-2022/containers"
+)
+
+// the diagram's trailing spaces matter, so they're spelt out
+const example = "    [D]    \n" +
+	"[N] [C]    \n" +
+	"[Z] [M] [P]\n" +
+	" 1   2   3 \n" +
+	"\n" +
+	"move 1 from 2 to 1\n" +
+	"move 3 from 1 to 3\n" +
+	"move 2 from 2 to 1\n" +
+	"move 1 from 1 to 2\n"
+
+func readExample(t *testing.T) ([]containers.Stack[Crate], []Move) {
+	crates, moves, err := readInput(strings.NewReader(example))
+	if err != nil {
+		t.Fatalf("failed to read input: %v", err)
+	}
+	return crates, moves
+}
+
+func TestCranes(t *testing.T) {
+	crates, moves := readExample(t)
+
+	for _, c := range []struct {
+		crane    Crane
+		expected string
+	}{
+		{CrateMover9000{}, "CMZ"},
+		{CrateMover9001{}, "MCD"},
+		{LimitedCrane{1}, "CMZ"},
+		{LimitedCrane{2}, "MCZ"},
+		{LimitedCrane{3}, "MCD"},
+	} {
+		top, err := rearrange(c.crane, cloneCrates(crates), moves)
+		if err != nil {
+			t.Errorf("%s failed: %v", c.crane.Name(), err)
+		} else if string(top) != c.expected {
+			t.Errorf("%s gave %s, expected %s", c.crane.Name(), string(top), c.expected)
+		}
+	}
+
+	if _, err := rearrange(CrateMover9000{}, cloneCrates(crates), []Move{{5, 0, 1}}); err == nil {
+		t.Error("expected an error moving more crates than there are")
+	}
+}
+
+func TestTraceUndoRedo(t *testing.T) {
+	crates, moves := readExample(t)
+	trace := NewTrace(CrateMover9001{}, crates)
+	for _, m := range moves {
+		if err := trace.Apply(m); err != nil {
+			t.Fatalf("failed to apply %v: %v", m, err)
+		}
+	}
+	final := cloneCrates(trace.State())
+
+	for trace.Undo() {
+	}
+	if trace.Position() != 0 || !reflect.DeepEqual(trace.State(), crates) {
+		t.Errorf("undoing everything gave %v at %d, expected %v", trace.State(), trace.Position(), crates)
+	}
+
+	for trace.Redo() {
+	}
+	if !reflect.DeepEqual(trace.State(), final) {
+		t.Errorf("redoing everything gave %v, expected %v", trace.State(), final)
+	}
+
+	// a new move after undoing replaces what was undone
+	trace.Undo()
+	trace.Undo()
+	if err := trace.Apply(Move{1, 2, 0}); err != nil {
+		t.Fatalf("failed to apply move: %v", err)
+	}
+	if len(trace.Moves()) != 3 || trace.Redo() {
+		t.Errorf("expected 3 moves and nothing to redo, got %v", trace.Moves())
+	}
+}
+
+func equalStacks(a []containers.Stack[Crate], b []containers.Stack[Crate]) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		// nil and empty stacks are the same
+		if len(a[i]) != len(b[i]) || (len(a[i]) > 0 && !reflect.DeepEqual(a[i], b[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPrintThenParse(t *testing.T) {
+	var buffer bytes.Buffer
+	crates, moves := readExample(t)
+	if err := writeInput(&buffer, crates, moves); err != nil {
+		t.Fatalf("failed to write input: %v", err)
+	}
+	if buffer.String() != example {
+		t.Errorf("printing the example gave:\n%s\nexpected:\n%s", buffer.String(), example)
+	}
+
+	rng := rand.New(rand.NewSource(5))
+	for n := 0; n < 200; n++ {
+		crates := make([]containers.Stack[Crate], 1+rng.Intn(12))
+		for i := range crates {
+			for j := rng.Intn(6); j > 0; j-- {
+				crates[i].Push(Crate('A' + rng.Intn(26)))
+			}
+		}
+		moves := make([]Move, rng.Intn(4))
+		for i := range moves {
+			moves[i] = Move{1 + rng.Intn(20), rng.Intn(len(crates)), rng.Intn(len(crates))}
+		}
+
+		buffer.Reset()
+		if err := writeInput(&buffer, crates, moves); err != nil {
+			t.Fatalf("failed to write input: %v", err)
+		}
+		printed := buffer.String()
+
+		parsedCrates, parsedMoves, err := readInput(strings.NewReader(printed))
+		if err != nil {
+			t.Fatalf("failed to parse printed input:\n%s\n%v", printed, err)
+		}
+		if !equalStacks(crates, parsedCrates) || !reflect.DeepEqual(moves, parsedMoves) {
+			t.Fatalf("parsing printed input:\n%s\ngave %v %v, expected %v %v", printed, parsedCrates, parsedMoves, crates, moves)
+		}
+	}
+}
+
+// blindGoal hides the estimate, turning the planner's A* into a plain
+// breadth-first search
+type blindGoal struct {
+	Goal
+}
+
+func (blindGoal) Estimate([]containers.Stack[Crate]) int {
+	return 0
+}
+
+func TestPlanIsShortest(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	for n := 0; n < 100; n++ {
+		crates := make([]containers.Stack[Crate], 2+rng.Intn(2))
+		for i := 0; i < 5; i++ {
+			stack := rng.Intn(len(crates))
+			crates[stack].Push(Crate('A' + rng.Intn(3)))
+		}
+
+		tops := make([]rune, len(crates))
+		for i := range tops {
+			tops[i] = []rune("ABC*.")[rng.Intn(5)]
+		}
+		goal, err := newTopsGoal(crates, string(tops))
+		if err != nil {
+			continue
+		}
+
+		for _, crane := range []Crane{CrateMover9000{}, CrateMover9001{}, LimitedCrane{2}} {
+			moves, err := plan(crane, crates, goal, 100000)
+			shortest, bfsErr := plan(crane, crates, blindGoal{goal}, 100000)
+			if (err == nil) != (bfsErr == nil) {
+				t.Fatalf("planning %s for %v with %s disagreed with BFS: %v, %v", string(tops), crates, crane.Name(), err, bfsErr)
+			}
+			if err != nil {
+				continue
+			}
+
+			if len(moves) != len(shortest) {
+				t.Errorf("planned %v for %s from %v with %s, but BFS found %v", moves, string(tops), crates, crane.Name(), shortest)
+			}
+
+			final := cloneCrates(crates)
+			if _, err := rearrange(crane, final, moves); err != nil || !goal.Reached(final) {
+				t.Errorf("plan %v for %s from %v with %s doesn't reach the goal", moves, string(tops), crates, crane.Name())
+			}
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"container/heap"
+	"fmt"
+	"strings"
+
+	"github.com/WJBarnes456/aoc-2022/containers"
+)
+
+// Goal is what the planner is searching for a state matching
+type Goal interface {
+	Reached(crates []containers.Stack[Crate]) bool
+	// Estimate must never be more than the moves still needed, for the plan
+	// to be the shortest
+	Estimate(crates []containers.Stack[Crate]) int
+}
+
+// Any stack can have any crate on top when planning for tops
+const ANY_CRATE, NO_CRATE = '*', '.'
+
+// TopsGoal wants particular crates on top of the stacks, like the puzzle's
+// answers. ANY_CRATE matches anything, and NO_CRATE an empty stack.
+type TopsGoal []Crate
+
+func (g TopsGoal) matches(crates []containers.Stack[Crate], i int) bool {
+	if g[i] == ANY_CRATE {
+		return true
+	}
+	top, ok := crates[i].TryPeek()
+	if !ok {
+		return g[i] == NO_CRATE
+	}
+	return top == g[i]
+}
+
+func (g TopsGoal) Reached(crates []containers.Stack[Crate]) bool {
+	return g.Estimate(crates) == 0
+}
+
+// every stack with the wrong top needs a move to or from it, and a move only
+// touches two stacks
+func (g TopsGoal) Estimate(crates []containers.Stack[Crate]) int {
+	wrong := 0
+	for i := range crates {
+		if !g.matches(crates, i) {
+			wrong++
+		}
+	}
+	return (wrong + 1) / 2
+}
+
+// ArrangementGoal wants every stack to be exactly as given
+type ArrangementGoal []containers.Stack[Crate]
+
+func (g ArrangementGoal) Reached(crates []containers.Stack[Crate]) bool {
+	return g.Estimate(crates) == 0
+}
+
+// as with tops, every stack which isn't right needs a move to or from it
+func (g ArrangementGoal) Estimate(crates []containers.Stack[Crate]) int {
+	wrong := 0
+	for i := range crates {
+		if stateKey(crates[i:i+1]) != stateKey(g[i:i+1]) {
+			wrong++
+		}
+	}
+	return (wrong + 1) / 2
+}
+
+// newTopsGoal checks the tops could be reached from the crates at all
+func newTopsGoal(crates []containers.Stack[Crate], tops string) (TopsGoal, error) {
+	goal := TopsGoal(tops)
+	if len(goal) != len(crates) {
+		return nil, fmt.Errorf("%d tops given for %d stacks", len(goal), len(crates))
+	}
+
+	available := crateCounts(crates)
+	for _, c := range goal {
+		if c != ANY_CRATE && c != NO_CRATE {
+			available[c]--
+			if available[c] < 0 {
+				return nil, fmt.Errorf("not enough %c crates for the tops %s", c, tops)
+			}
+		}
+	}
+	return goal, nil
+}
+
+// newArrangementGoal checks the arrangement has the same crates
+func newArrangementGoal(crates []containers.Stack[Crate], target []containers.Stack[Crate]) (ArrangementGoal, error) {
+	if len(target) != len(crates) {
+		return nil, fmt.Errorf("target has %d stacks, not %d", len(target), len(crates))
+	}
+
+	have, want := crateCounts(crates), crateCounts(target)
+	for c, count := range want {
+		if have[c] != count {
+			return nil, fmt.Errorf("target has %d %c crates, not %d", count, c, have[c])
+		}
+	}
+	for c, count := range have {
+		if want[c] != count {
+			return nil, fmt.Errorf("target has %d %c crates, not %d", want[c], c, count)
+		}
+	}
+	return ArrangementGoal(target), nil
+}
+
+func crateCounts(crates []containers.Stack[Crate]) map[Crate]int {
+	counts := map[Crate]int{}
+	for _, stack := range crates {
+		for _, c := range stack {
+			counts[c]++
+		}
+	}
+	return counts
+}
+
+// stateKey packs the stacks into a string, with newlines between stacks as
+// they can never be crates
+func stateKey(crates []containers.Stack[Crate]) string {
+	var b strings.Builder
+	for _, stack := range crates {
+		for _, c := range stack {
+			b.WriteRune(rune(c))
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+type planNode struct {
+	crates []containers.Stack[Crate]
+	key    string
+	moves  int
+	// moves plus the estimate of those left
+	priority int
+}
+
+type planQueue []*planNode
+
+func (q planQueue) Len() int { return len(q) }
+func (q planQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority < q[j].priority
+	}
+	// prefer whatever's got further, as it's likely closer to the goal
+	return q[i].moves > q[j].moves
+}
+func (q planQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *planQueue) Push(x any)   { *q = append(*q, x.(*planNode)) }
+func (q *planQueue) Pop() any {
+	old := *q
+	last := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return last
+}
+
+type step struct {
+	from string
+	move Move
+}
+
+// plan searches for the fewest moves taking the crates to the goal with the
+// crane, using A*. The number of arrangements grows very quickly with the
+// number of crates, so it gives up after visiting maxStates of them.
+func plan(crane Crane, crates []containers.Stack[Crate], goal Goal, maxStates int) ([]Move, error) {
+	start := &planNode{crates: cloneCrates(crates), key: stateKey(crates)}
+	start.priority = goal.Estimate(crates)
+
+	best := map[string]int{start.key: 0}
+	steps := map[string]step{}
+	queue := &planQueue{start}
+
+	for visited := 0; queue.Len() > 0; visited++ {
+		if visited >= maxStates {
+			return nil, fmt.Errorf("gave up after visiting %d arrangements", maxStates)
+		}
+
+		node := heap.Pop(queue).(*planNode)
+		if node.moves > best[node.key] {
+			// already reached this arrangement in fewer moves
+			continue
+		}
+
+		if goal.Reached(node.crates) {
+			return replay(steps, node.key, start.key), nil
+		}
+
+		for source, stack := range node.crates {
+			for destination := range node.crates {
+				if source == destination {
+					continue
+				}
+
+				for count := 1; count <= stack.Size(); count++ {
+					m := Move{count, source, destination}
+					next := cloneCrates(node.crates)
+					if err := crane.Apply(next, m); err != nil {
+						return nil, err
+					}
+
+					key := stateKey(next)
+					if moves, seen := best[key]; seen && moves <= node.moves+1 {
+						continue
+					}
+					best[key] = node.moves + 1
+					steps[key] = step{node.key, m}
+					heap.Push(queue, &planNode{next, key, node.moves + 1, node.moves + 1 + goal.Estimate(next)})
+				}
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("the goal can't be reached")
+}
+
+// replay walks back from the end to the start to list the moves taken
+func replay(steps map[string]step, end string, start string) []Move {
+	moves := []Move{}
+	for key := end; key != start; key = steps[key].from {
+		moves = append(moves, steps[key].move)
+	}
+
+	for i, j := 0, len(moves)-1; i < j; i, j = i+1, j-1 {
+		moves[i], moves[j] = moves[j], moves[i]
+	}
+	return moves
+}
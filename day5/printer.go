@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/WJBarnes456/aoc-2022/containers"
+)
+
+// diagramLines renders the stacks as the puzzle draws them: crates in
+// brackets, the top of each stack highest, and the stacks numbered along the
+// bottom. Every line is padded to the full width, as in the puzzle input.
+func diagramLines(crates []containers.Stack[Crate]) []string {
+	height := 0
+	for _, stack := range crates {
+		if stack.Size() > height {
+			height = stack.Size()
+		}
+	}
+
+	lines := make([]string, 0, height+1)
+	cells := make([]string, len(crates))
+	for level := height - 1; level >= 0; level-- {
+		for i, stack := range crates {
+			if level < stack.Size() {
+				cells[i] = "[" + string(stack[level]) + "]"
+			} else {
+				cells[i] = "   "
+			}
+		}
+		lines = append(lines, strings.Join(cells, " "))
+	}
+
+	for i := range crates {
+		cells[i] = fmt.Sprintf(" %-2d", i+1)
+	}
+	return append(lines, strings.Join(cells, " "))
+}
+
+func writeDiagram(w io.Writer, crates []containers.Stack[Crate]) error {
+	_, err := fmt.Fprintln(w, strings.Join(diagramLines(crates), "\n"))
+	return err
+}
+
+func writeMoves(w io.Writer, moves []Move) error {
+	for _, m := range moves {
+		if _, err := fmt.Fprintln(w, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeInput writes the stacks and moves in the same format readInput reads
+func writeInput(w io.Writer, crates []containers.Stack[Crate], moves []Move) error {
+	if err := writeDiagram(w, crates); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(w); err != nil {
+		return err
+	}
+	return writeMoves(w, moves)
+}
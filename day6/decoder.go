@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/WJBarnes456/aoc-2022/containers"
+)
+
+// Decoder splits a signal into packets. Markers come in runs, as shifting a
+// window of distinct symbols along often keeps them distinct, so it's where a
+// run starts that counts:
+//
+//   - a packet starts after the first start-of-packet marker of a run
+//   - its message starts after the first start-of-message marker following
+//     that
+//   - the message runs up to where the next packet's marker starts
+//
+// The first packet's positions are the puzzle's answers. Packets which don't
+// fit this are still decoded, with err saying what's wrong with them.
+type Decoder struct {
+	symbols symbols
+	unit    Unit
+
+	packet, message     *Detector
+	inPacket, inMessage bool
+
+	// the packet being read, and its message so far
+	current  *Packet
+	contents []rune
+
+	decoded Packet
+	err     error
+	done    bool
+}
+
+func NewDecoder(r io.Reader, unit Unit) *Decoder {
+	// neither marker length can be invalid
+	packet, _ := NewDetector(PACKET_MARKER)
+	message, _ := NewDetector(MESSAGE_MARKER)
+	return &Decoder{symbols: newSymbols(r, unit), unit: unit, packet: packet, message: message}
+}
+
+// Next decodes the next packet, returning false once the signal has ended or
+// it failed to read it
+func (d *Decoder) Next() bool {
+	for !d.done {
+		c, err := d.symbols.next()
+		if err != nil {
+			d.done = true
+			if err != io.EOF {
+				d.err = fmt.Errorf("failed to read signal: %v", err)
+			}
+			return d.finish(d.packet.Offset())
+		}
+
+		if d.step(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// Packet is the packet Next just decoded
+func (d *Decoder) Packet() Packet {
+	return d.decoded
+}
+
+// Err is whatever stopped the decoder reading the signal, or nil if it reached
+// the end. Malformed packets don't stop it.
+func (d *Decoder) Err() error {
+	return d.err
+}
+
+// Packets iterates over the rest of the packets, after which Err says whether
+// it got to the end of the signal
+func (d *Decoder) Packets() containers.Seq[Packet] {
+	return func(yield func(Packet) bool) {
+		for d.Next() {
+			if !yield(d.Packet()) {
+				return
+			}
+		}
+	}
+}
+
+// step takes the next symbol, returning whether it finished a packet
+func (d *Decoder) step(c rune) bool {
+	isPacket, isMessage := d.packet.Step(c), d.message.Step(c)
+	packetStarts, messageStarts := isPacket && !d.inPacket, isMessage && !d.inMessage
+	d.inPacket, d.inMessage = isPacket, isMessage
+	offset := d.packet.Offset()
+
+	if packetStarts {
+		finished := d.finish(offset - PACKET_MARKER)
+		d.current = &Packet{startPosition: offset, messagePosition: -1}
+		d.contents = d.contents[:0]
+		return finished
+	}
+
+	switch {
+	case d.current == nil:
+		// noise before the first packet
+	case d.current.messagePosition == -1:
+		if messageStarts {
+			d.current.messagePosition = offset
+		}
+	default:
+		// any start-of-message markers in here are just part of the message
+		d.contents = append(d.contents, c)
+	}
+	return false
+}
+
+// finish closes the current packet, given where the next packet's marker
+// starts, returning whether there was one to close
+func (d *Decoder) finish(next int) bool {
+	if d.current == nil {
+		return false
+	}
+
+	p := *d.current
+	d.current = nil
+	switch {
+	case p.messagePosition == -1 && d.done:
+		p.err = fmt.Errorf("packet at %d has no start-of-message marker before the signal ends", p.startPosition)
+	case p.messagePosition == -1:
+		p.err = fmt.Errorf("packet at %d has no start-of-message marker before the next packet's at %d", p.startPosition, next+PACKET_MARKER)
+	case next < p.messagePosition:
+		p.err = fmt.Errorf("packet at %d overlaps its start-of-message marker at %d with the next packet's marker at %d", p.startPosition, p.messagePosition, next+PACKET_MARKER)
+	default:
+		p.contents = d.encode(d.contents[:next-p.messagePosition])
+	}
+
+	d.decoded = p
+	return true
+}
+
+// encode turns the symbols back into the bytes they were read from
+func (d *Decoder) encode(symbols []rune) []byte {
+	if d.unit == RUNES {
+		return []byte(string(symbols))
+	}
+
+	out := make([]byte, len(symbols))
+	for i, c := range symbols {
+		out[i] = byte(c)
+	}
+	return out
+}
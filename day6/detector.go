@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/WJBarnes456/aoc-2022/containers"
+)
+
+// Unit is what a stream is split into, and so what offsets count
+type Unit int
+
+const (
+	RUNES Unit = iota
+	BYTES
+)
+
+// Detector spots markers, where the last length symbols are all different, as
+// the signal arrives one symbol at a time. It counts how many of each symbol
+// are in the window, and how many symbols appear more than once, so each step
+// is O(1) however long the marker is.
+type Detector struct {
+	window *containers.RingBuffer[rune]
+	// every byte has a count in the array, so counting bytes never touches
+	// the map
+	small    [256]int
+	large    map[rune]int
+	repeated int
+	offset   int
+}
+
+func NewDetector(length int) (*Detector, error) {
+	if length < 1 {
+		return nil, fmt.Errorf("markers must be at least 1 long, not %d", length)
+	}
+	return &Detector{
+		window: containers.NewRingBuffer[rune](length),
+		large:  map[rune]int{},
+	}, nil
+}
+
+// adjust changes how many of c are in the window, returning the new count
+func (d *Detector) adjust(c rune, delta int) int {
+	if 0 <= c && int(c) < len(d.small) {
+		d.small[c] += delta
+		return d.small[c]
+	}
+
+	count := d.large[c] + delta
+	if count == 0 {
+		delete(d.large, c)
+	} else {
+		d.large[c] = count
+	}
+	return count
+}
+
+// Step adds the next symbol, returning whether the window is now a marker
+func (d *Detector) Step(c rune) bool {
+	d.offset++
+	if old, dropped := d.window.Push(c); dropped {
+		if d.adjust(old, -1) == 1 {
+			d.repeated--
+		}
+	}
+	if d.adjust(c, 1) == 2 {
+		d.repeated++
+	}
+	return d.window.Full() && d.repeated == 0
+}
+
+// Offset is how many symbols have been stepped through, which is the position
+// just after the marker when Step returns true
+func (d *Detector) Offset() int {
+	return d.offset
+}
+
+// symbols reads the stream one symbol at a time. Invalid UTF-8 comes through
+// as utf8.RuneError when reading runes, so it still takes up an offset.
+type symbols struct {
+	r    *bufio.Reader
+	unit Unit
+}
+
+func newSymbols(r io.Reader, unit Unit) symbols {
+	return symbols{bufio.NewReader(r), unit}
+}
+
+// next reads the next symbol. The signal is a single line, so the line's end
+// is the end of the signal, rather than a symbol to look for markers in.
+func (s symbols) next() (rune, error) {
+	var c rune
+	var err error
+	if s.unit == BYTES {
+		var b byte
+		b, err = s.r.ReadByte()
+		c = rune(b)
+	} else {
+		c, _, err = s.r.ReadRune()
+	}
+
+	if err == nil && (c == '\r' || c == '\n') {
+		return 0, io.EOF
+	}
+	return c, err
+}
+
+// detectMarkers runs the detector over the stream, calling emit with the
+// offset of every marker as soon as it's seen. It stops at the end of the
+// stream, or early if emit returns false.
+func detectMarkers(r io.Reader, unit Unit, length int, emit func(offset int) bool) error {
+	d, err := NewDetector(length)
+	if err != nil {
+		return err
+	}
+
+	s := newSymbols(r, unit)
+	for {
+		c, err := s.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read signal: %v", err)
+		}
+
+		if d.Step(c) && !emit(d.Offset()) {
+			return nil
+		}
+	}
+}
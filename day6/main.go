@@ -1,16 +1,32 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"io"
 	"os"
+
+	"github.com/WJBarnes456/aoc-2022/params"
+)
+
+const PACKET_MARKER, MESSAGE_MARKER = 4, 14
+
+var (
+	markerLength = flag.Int("markers", 0, "print the offset of every marker of this length as soon as it's seen, instead of solving")
+	countBytes   = flag.Bool("bytes", false, "count offsets in bytes rather than runes")
+	decode       = flag.Bool("decode", false, "print every packet's message instead of solving, reporting malformed packets to stderr")
 )
 
 type Packet struct {
 	// Position for the start of the packet contents
 	startPosition int
-	// Contents of the packet
-	// contents []rune
+	// Position for the start of the message, just after its marker, or -1
+	// if the packet never got that far
+	messagePosition int
+	// Contents of the message, up to where the next packet's marker starts
+	contents []byte
+	// Why the packet is malformed, if it is
+	err error
 }
 
 // this is O(n^2), but for small numbers of characters is probably better than building + maintaining a map
@@ -36,7 +52,8 @@ func identifyPackets(buffer []rune, headerLength int) ([]Packet, error) {
 
 	packets := []Packet{}
 	start := -1
-	for i := headerLength; i < len(buffer); i++ {
+	// nb. <= so that a header ending on the final character still counts
+	for i := headerLength; i <= len(buffer); i++ {
 		potentialHeader := buffer[i-headerLength : i]
 
 		// nothing to do if this is part of the previous packet
@@ -46,45 +63,92 @@ func identifyPackets(buffer []rune, headerLength int) ([]Packet, error) {
 
 		// save the previous one
 		if start != -1 {
-			packets = append(packets, Packet{start})
+			packets = append(packets, Packet{startPosition: start, messagePosition: -1})
 		}
 		start = i
 	}
 
 	if start != -1 {
-		packets = append(packets, Packet{start})
+		packets = append(packets, Packet{startPosition: start, messagePosition: -1})
 	}
 
 	return packets, nil
 }
 
-// Because "The signal is a series of seemingly-random characters that the device receives one at a time."
-// I was sorely tempted to make this run online (i.e. run on stdin one byte at a time), but I resisted.
+func printPackets(d *Decoder) error {
+	d.Packets()(func(p Packet) bool {
+		if p.err != nil {
+			fmt.Fprintln(os.Stderr, "malformed packet:", p.err)
+		} else {
+			fmt.Printf("Packet at %d, message at %d: %q\n", p.startPosition, p.messagePosition, p.contents)
+		}
+		return true
+	})
+	return d.Err()
+}
+
+// "The signal is a series of seemingly-random characters that the device
+// receives one at a time", so this reads stdin one symbol at a time and
+// stops as soon as it's found both markers
 func run() error {
-	buffer, err := io.ReadAll(os.Stdin)
+	if err := params.Parse(); err != nil {
+		return fmt.Errorf("failed to parse parameters: %v", err)
+	}
 
-	if err != nil {
-		return fmt.Errorf("failed to read stdin to buffer: %v", err)
+	unit := RUNES
+	if *countBytes {
+		unit = BYTES
 	}
 
-	input := string(buffer)
+	if *markerLength > 0 {
+		return detectMarkers(os.Stdin, unit, *markerLength, func(offset int) bool {
+			fmt.Println(offset)
+			return true
+		})
+	}
 
-	part1, err := identifyPackets([]rune(input), 4)
+	if *decode {
+		return printPackets(NewDecoder(os.Stdin, unit))
+	}
 
+	packet, err := NewDetector(PACKET_MARKER)
 	if err != nil {
-		return fmt.Errorf("failed to identify packets: %v", err)
+		return err
 	}
-
-	fmt.Println("Part 1:", part1[0].startPosition)
-
-	part2, err := identifyPackets([]rune(input), 14)
-
+	message, err := NewDetector(MESSAGE_MARKER)
 	if err != nil {
-		return fmt.Errorf("failed to identify packets: %v", err)
+		return err
 	}
 
-	fmt.Println("Part 2:", part2[0].startPosition)
+	part1, part2 := -1, -1
+	s := newSymbols(os.Stdin, unit)
+	for part1 == -1 || part2 == -1 {
+		c, err := s.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read stdin: %v", err)
+		}
 
+		// a start-of-message marker always contains a start-of-packet
+		// marker ending in the same place, so part 1 is found first
+		if packet.Step(c) && part1 == -1 {
+			part1 = packet.Offset()
+			fmt.Println("Part 1:", part1)
+		}
+		if message.Step(c) && part2 == -1 {
+			part2 = message.Offset()
+			fmt.Println("Part 2:", part2)
+		}
+	}
+
+	if part1 == -1 {
+		return fmt.Errorf("no start-of-packet marker in the signal")
+	}
+	if part2 == -1 {
+		return fmt.Errorf("no start-of-message marker in the signal")
+	}
 	return nil
 }
 
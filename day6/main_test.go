@@ -0,0 +1,215 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/WJBarnes456/aoc-2022/difftest"
+)
+
+type signal struct {
+	buffer       string
+	headerLength int
+}
+
+// the position just after the first marker, or -1 if there isn't one
+func firstMarker(s signal) int {
+	packets, err := identifyPackets([]rune(s.buffer), s.headerLength)
+	if err != nil || len(packets) == 0 {
+		return -1
+	}
+	return packets[0].startPosition
+}
+
+// every marker the detector emits, which should be every packet
+// identifyPackets finds
+func streamedMarkers(s signal) []int {
+	offsets := []int{}
+	detectMarkers(strings.NewReader(s.buffer), RUNES, s.headerLength, func(offset int) bool {
+		offsets = append(offsets, offset)
+		return true
+	})
+	return offsets
+}
+
+func allMarkers(s signal) []int {
+	offsets := []int{}
+	packets, _ := identifyPackets([]rune(s.buffer), s.headerLength)
+	for _, p := range packets {
+		offsets = append(offsets, p.startPosition)
+	}
+	return offsets
+}
+
+func streamedFirstMarker(s signal) int {
+	first := -1
+	detectMarkers(strings.NewReader(s.buffer), RUNES, s.headerLength, func(offset int) bool {
+		first = offset
+		return false
+	})
+	return first
+}
+
+// a naive reference, checking every window with a set
+func referenceFirstMarker(s signal) int {
+	runes := []rune(s.buffer)
+	for end := s.headerLength; end <= len(runes); end++ {
+		seen := map[rune]struct{}{}
+		for _, c := range runes[end-s.headerLength : end] {
+			seen[c] = struct{}{}
+		}
+		if len(seen) == s.headerLength {
+			return end
+		}
+	}
+	return -1
+}
+
+func TestFirstMarkerMatchesReference(t *testing.T) {
+	h := difftest.Harness[signal, int]{
+		Shrink: func(s signal) []signal {
+			candidates := []signal{}
+			for _, shorter := range difftest.ShrinkSlice([]rune(s.buffer)) {
+				candidates = append(candidates, signal{string(shorter), s.headerLength})
+			}
+			return candidates
+		},
+	}
+	h.Register("identifyPackets", firstMarker)
+	h.Register("detector", streamedFirstMarker)
+	h.Register("reference", referenceFirstMarker)
+
+	h.Check(t,
+		signal{"mjqjpqmgbljsphdztnvjfqwrcgsmlb", 4},
+		signal{"bvwbjplbgvbhsrlpgdmjqwftvncz", 4},
+		signal{"mjqjpqmgbljsphdztnvjfqwrcgsmlb", 14},
+		signal{"abcd", 4},
+	)
+
+	h.Generate(t, 6, 1000, generateSignal)
+}
+
+// identifyPackets used to stop before the window ending on the last symbol,
+// so a marker right at the end of the signal went unseen
+func TestIdentifyPacketsAtEnd(t *testing.T) {
+	for _, c := range []struct {
+		buffer       string
+		headerLength int
+		expected     []int
+	}{
+		{"abcd", 4, []int{4}},
+		{"aabc", 3, []int{4}},
+		{"aaab", 2, []int{4}},
+		{"aaaa", 2, []int{}},
+	} {
+		packets, err := identifyPackets([]rune(c.buffer), c.headerLength)
+		if err != nil {
+			t.Fatalf("failed to identify packets in %q: %v", c.buffer, err)
+		}
+		starts := []int{}
+		for _, p := range packets {
+			starts = append(starts, p.startPosition)
+		}
+		if !reflect.DeepEqual(starts, c.expected) {
+			t.Errorf("packets in %q with headers of %d start at %v, expected %v", c.buffer, c.headerLength, starts, c.expected)
+		}
+	}
+}
+
+func generateSignal(rng *rand.Rand) signal {
+	// a small alphabet makes repeats, and so late markers, likely
+	buffer := make([]rune, rng.Intn(30))
+	for i := range buffer {
+		buffer[i] = rune('a' + rng.Intn(6))
+	}
+	return signal{string(buffer), 2 + rng.Intn(3)}
+}
+
+func TestDetectorFindsEveryMarker(t *testing.T) {
+	h := difftest.Harness[signal, []int]{}
+	h.Register("identifyPackets", allMarkers)
+	h.Register("detector", streamedMarkers)
+	h.Generate(t, 44, 1000, generateSignal)
+}
+
+func TestDetectorUnits(t *testing.T) {
+	// é is two bytes, so the units disagree on both where the markers are
+	// and how many there are
+	signal := "ééabé"
+	for _, c := range []struct {
+		unit     Unit
+		expected []int
+	}{
+		{RUNES, []int{4, 5}},
+		{BYTES, []int{5, 6, 7, 8}},
+	} {
+		offsets := []int{}
+		err := detectMarkers(strings.NewReader(signal), c.unit, 3, func(offset int) bool {
+			offsets = append(offsets, offset)
+			return true
+		})
+		if err != nil || !reflect.DeepEqual(offsets, c.expected) {
+			t.Errorf("detected %v, %v in unit %d, expected %v", offsets, err, c.unit, c.expected)
+		}
+	}
+
+	// nor is a line's end a symbol which could finish a marker
+	offsets := []int{}
+	err := detectMarkers(strings.NewReader("abc\n"), RUNES, 4, func(offset int) bool {
+		offsets = append(offsets, offset)
+		return true
+	})
+	if err != nil || len(offsets) != 0 {
+		t.Errorf("detected %v, %v after a line's end", offsets, err)
+	}
+
+	if _, err := NewDetector(0); err == nil {
+		t.Error("expected an error for an empty marker")
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	type decoded struct {
+		start, message int
+		contents       string
+		malformed      bool
+	}
+
+	for _, c := range []struct {
+		signal   string
+		unit     Unit
+		expected []decoded
+	}{
+		// the first packet is the puzzle's answers
+		{"mjqjpqmgbljsphdztnvjfqwrcgsmlb", RUNES, []decoded{{7, 19, "jfqwrcgsmlb", false}}},
+		// a repeat ends the first message's run of markers, so the next
+		// packet can start
+		{"aaaabcdefghijklmnhi!!wxyzabcdefghijklmnopbye", RUNES, []decoded{
+			{7, 17, "hi!", false},
+			{24, 34, "jklmnopbye", false},
+		}},
+		// the second packet's marker starts inside the first's message marker
+		{"zzabcdefghijklmlxy", RUNES, []decoded{
+			{5, 15, "", true},
+			{18, -1, "", true},
+		}},
+		{"abcdefghijklmné", RUNES, []decoded{{4, 14, "é", false}}},
+		{"abcdefghijklmné", BYTES, []decoded{{4, 14, "é", false}}},
+		{"aaaa", RUNES, []decoded{}},
+		// the line's end isn't part of the message
+		{"mjqjpqmgbljsphdztnvjfqwrcgsmlb\n", RUNES, []decoded{{7, 19, "jfqwrcgsmlb", false}}},
+		{"mjqjpqmgbljsphdztnvjfqwrcgsmlb\r\n", BYTES, []decoded{{7, 19, "jfqwrcgsmlb", false}}},
+	} {
+		d := NewDecoder(strings.NewReader(c.signal), c.unit)
+		got := []decoded{}
+		d.Packets()(func(p Packet) bool {
+			got = append(got, decoded{p.startPosition, p.messagePosition, string(p.contents), p.err != nil})
+			return true
+		})
+		if d.Err() != nil || !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("decoding %q in unit %d gave %v, %v, expected %v", c.signal, c.unit, got, d.Err(), c.expected)
+		}
+	}
+}
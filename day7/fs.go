@@ -0,0 +1,192 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FS exposes a parsed directory tree as a read-only io/fs file system, so the
+// standard library's walking and globbing work on it. The transcript only
+// gives each file's size, so reading a file gives that many zero bytes.
+// Directories report the total size of everything in them, like part 1.
+type FS struct {
+	root *Directory
+}
+
+func NewFS(root *Directory) FS {
+	return FS{root}
+}
+
+// lookup finds what's at the path, which is a directory unless file is set
+func (f FS) lookup(op string, name string) (*Directory, *File, error) {
+	if !fs.ValidPath(name) {
+		return nil, nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+
+	dir := f.root
+	if name == "." {
+		return dir, nil, nil
+	}
+
+	segments := strings.Split(name, "/")
+	for i, segment := range segments {
+		if sub, exists := dir.directories[segment]; exists {
+			dir = sub
+			continue
+		}
+
+		// files can only be the last segment
+		if i == len(segments)-1 {
+			for j := range dir.files {
+				if dir.files[j].name == segment {
+					return nil, &dir.files[j], nil
+				}
+			}
+		}
+		return nil, nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return dir, nil, nil
+}
+
+func (f FS) Open(name string) (fs.File, error) {
+	dir, file, err := f.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if file != nil {
+		return &openFile{info: fileInfoOf(file)}, nil
+	}
+	return &openDir{path: name, info: dirInfoOf(dir, name), entries: entriesOf(dir)}, nil
+}
+
+func (f FS) Stat(name string) (fs.FileInfo, error) {
+	dir, file, err := f.lookup("stat", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if file != nil {
+		return fileInfoOf(file), nil
+	}
+	return dirInfoOf(dir, name), nil
+}
+
+func (f FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	dir, file, err := f.lookup("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if file != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	return entriesOf(dir), nil
+}
+
+type fileInfo struct {
+	name string
+	size int
+	dir  bool
+}
+
+func fileInfoOf(f *File) fileInfo {
+	return fileInfo{f.name, f.Size(), false}
+}
+
+// the root's name is "/" in the transcript, but "." to io/fs
+func dirInfoOf(d *Directory, name string) fileInfo {
+	if name == "." {
+		return fileInfo{".", d.Size(), true}
+	}
+	return fileInfo{d.name, d.Size(), true}
+}
+
+func (i fileInfo) Name() string       { return i.name }
+func (i fileInfo) Size() int64        { return int64(i.size) }
+func (i fileInfo) ModTime() time.Time { return time.Time{} }
+func (i fileInfo) IsDir() bool        { return i.dir }
+func (i fileInfo) Sys() any           { return nil }
+func (i fileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+// entriesOf lists everything in the directory, sorted by name as io/fs expects
+func entriesOf(d *Directory) []fs.DirEntry {
+	entries := make([]fs.DirEntry, 0, len(d.files)+len(d.directories))
+	for i := range d.files {
+		entries = append(entries, fs.FileInfoToDirEntry(fileInfoOf(&d.files[i])))
+	}
+	for _, sub := range d.directories {
+		entries = append(entries, fs.FileInfoToDirEntry(fileInfo{sub.name, sub.Size(), true}))
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries
+}
+
+type openFile struct {
+	info   fileInfo
+	offset int
+}
+
+func (f *openFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *openFile) Close() error               { return nil }
+
+func (f *openFile) Read(b []byte) (int, error) {
+	if f.offset >= f.info.size {
+		return 0, io.EOF
+	}
+
+	n := len(b)
+	if remaining := f.info.size - f.offset; n > remaining {
+		n = remaining
+	}
+	for i := 0; i < n; i++ {
+		b[i] = 0
+	}
+	f.offset += n
+	return n, nil
+}
+
+type openDir struct {
+	path    string
+	info    fileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *openDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *openDir) Close() error               { return nil }
+
+func (d *openDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.path, Err: errors.New("is a directory")}
+}
+
+// ReadDir follows fs.ReadDirFile: n > 0 reads at most n entries at a time,
+// with io.EOF once there are none left, and n <= 0 reads all the rest
+func (d *openDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	remaining := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return remaining, nil
+	}
+
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(remaining) {
+		n = len(remaining)
+	}
+	d.offset += n
+	return remaining[:n], nil
+}
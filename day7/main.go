@@ -1,13 +1,31 @@
 package main
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
-	"io"
+	"io/fs"
 	"os"
 	"sort"
-	"strconv"
 	"strings"
+
+	"github.com/WJBarnes456/aoc-2022/params"
+)
+
+var (
+	threshold = flag.Int("threshold", 100000, "size below which directories are counted in part 1")
+	diskSize  = flag.Int("disk", 70000000, "total size of the disk")
+	required  = flag.Int("required", 30000000, "unused space required to run the update")
+	du        = flag.Bool("du", false, "list every directory's size, like du, instead of solving")
+	tree      = flag.Bool("tree", false, "draw the tree of files and directories, largest first, instead of solving")
+	depth     = flag.Int("depth", 0, "how many levels of the tree to draw, or 0 for all of them")
+	below     = flag.Int("below", 0, "only draw what's smaller than this in the tree, like part 1")
+	plan      = flag.Bool("plan", false, "plan which directories to delete to free enough space, allowing more than one, instead of solving")
+	objective = flag.String("objective", "space", "what the plan keeps down: space, to free as little as possible, or count, to delete the fewest directories")
+	protect   = flag.String("protect", "", "comma-separated absolute paths which the plan mustn't delete")
+	maxVisits = flag.Int("max-visits", 10000000, "most states to search through when planning")
+	lenient   = flag.Bool("lenient", false, "skip over anything in the transcript which can't be understood, warning about it on stderr")
+	rewrite   = flag.Bool("transcript", false, "write the tree back out as a transcript in the puzzle's format instead of solving")
+	glob      = flag.String("glob", "", "print the size of everything matching this pattern, like a/*/d.log, instead of solving")
 )
 
 // Treating files and directories as separate types makes the typing simpler
@@ -18,6 +36,14 @@ type Directory struct {
 	files       []File
 	directories map[string]*Directory
 	parent      *Directory
+	// the size is worked out once, bottom-up, the first time it's asked for,
+	// so the tree mustn't change after that
+	size  int
+	sized bool
+}
+
+func newDirectory(name string, parent *Directory) *Directory {
+	return &Directory{name: name, files: []File{}, directories: map[string]*Directory{}, parent: parent}
 }
 
 type File struct {
@@ -30,6 +56,10 @@ func (f *File) Size() int {
 }
 
 func (d *Directory) Size() int {
+	if d.sized {
+		return d.size
+	}
+
 	total := 0
 	for _, f := range d.files {
 		total += f.Size()
@@ -39,111 +69,10 @@ func (d *Directory) Size() int {
 		total += d2.Size()
 	}
 
+	d.size, d.sized = total, true
 	return total
 }
 
-// Returns whether there's a next line, what it is if so, then the list of files and directory names, then an error
-// This is because we don't know if we've reached the end of an ls until we reach it
-func parseLs(scanner *bufio.Scanner) (bool, string, []File, []string, error) {
-	files, directories := []File{}, []string{}
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		parts := strings.Split(line, " ")
-
-		if parts[0] == "$" && len(parts) == 3 {
-			return true, line, files, directories, nil
-		}
-
-		if parts[0] == "dir" && len(parts) == 2 {
-			directories = append(directories, parts[1])
-		} else {
-			// In all other cases, assume it's a file
-			// this isn't very defensive, but it'll work
-			size, err := strconv.ParseInt(parts[0], 10, 32)
-
-			if err != nil {
-				return false, "", files, directories, fmt.Errorf("failed to parse file %s size: %v", parts[1], err)
-			}
-
-			files = append(files, File{parts[1], int(size)})
-		}
-	}
-
-	return false, "", files, directories, nil
-}
-
-func parseCd(parts []string, cwd **Directory, root *Directory) error {
-	if parts[2] == "/" {
-		(*cwd) = root
-		return nil
-	}
-
-	if parts[2] == ".." {
-		(*cwd) = (*cwd).parent
-		return nil
-	}
-
-	dest, exists := (*cwd).directories[parts[2]]
-
-	if !exists {
-		return fmt.Errorf("attempted to descend to non-existent directory %v", dest)
-	}
-
-	(*cwd) = dest
-	return nil
-}
-
-func parseFilesystem(r io.Reader) (Directory, error) {
-	scanner := bufio.NewScanner(r)
-
-	root := Directory{"/", []File{}, map[string]*Directory{}, nil}
-
-	var cwd *Directory
-
-	validLine := scanner.Scan()
-	line := scanner.Text()
-
-	for validLine {
-		parts := strings.Split(line, " ")
-
-		if parts[0] != "$" {
-			return root, fmt.Errorf("parser encountered a non-command %s", line)
-		}
-
-		if parts[1] == "cd" && len(parts) == 3 {
-			parseCd(parts, &cwd, &root)
-			validLine, line = scanner.Scan(), scanner.Text()
-			continue
-		}
-
-		if parts[1] == "ls" && len(parts) == 2 {
-			// parseLs is a bit of a beast, because you can't tell if you've seen the end of an ls until you've actually seen it
-			validNextLine, nextLine, files, directoryNames, err := parseLs(scanner)
-
-			if err != nil {
-				return root, fmt.Errorf("failed to parse ls: %v", err)
-			}
-
-			cwd.files = files
-
-			for _, name := range directoryNames {
-				_, dirExists := cwd.directories[name]
-				if !dirExists {
-					cwd.directories[name] = &Directory{name, []File{}, map[string]*Directory{}, cwd}
-				}
-			}
-
-			validLine, line = validNextLine, nextLine
-			continue
-		}
-
-		return root, fmt.Errorf("invalid command %s", line)
-	}
-
-	return root, nil
-}
-
 func allDirectories(rootDir *Directory) []*Directory {
 	// Breadth-first search over the nodes
 	nodes := []*Directory{rootDir}
@@ -156,13 +85,13 @@ func allDirectories(rootDir *Directory) []*Directory {
 	return nodes
 }
 
-func part1(rootDir *Directory) int {
+func part1(rootDir *Directory, threshold int) int {
 	dirs := allDirectories(rootDir)
 
 	total := 0
 	for _, d := range dirs {
 		size := d.Size()
-		if size < 100000 {
+		if size < threshold {
 			total += size
 		}
 	}
@@ -170,10 +99,7 @@ func part1(rootDir *Directory) int {
 	return total
 }
 
-func part2(rootDir *Directory) int {
-	total := 70000000
-	required := 30000000
-
+func part2(rootDir *Directory, total int, required int) int {
 	// we need usedSpace -x + required <= total
 	// smallest x such that -x <= total - required - usedSpace
 	// i.e. x such that x >= usedSpace + required - total
@@ -195,21 +121,83 @@ func part2(rootDir *Directory) int {
 	return 0
 }
 
+func printPlan(rootDir *Directory) error {
+	protected := []string{}
+	if *protect != "" {
+		protected = strings.Split(*protect, ",")
+	}
+	if err := checkProtected(NewFS(rootDir), protected); err != nil {
+		return err
+	}
+
+	objective, err := objectiveNamed(*objective)
+	if err != nil {
+		return err
+	}
+
+	need := rootDir.Size() + *required - *diskSize
+	plan, err := planDeletion(rootDir, need, objective, protected, *maxVisits)
+	if err != nil {
+		return fmt.Errorf("failed to plan deletion: %v", err)
+	}
+	return writePlan(os.Stdout, plan)
+}
+
+func printGlob(fsys FS, pattern string) error {
+	matches, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return fmt.Errorf("failed to match %s: %v", pattern, err)
+	}
+
+	for _, match := range matches {
+		info, err := fs.Stat(fsys, match)
+		if err != nil {
+			return err
+		}
+		fmt.Println(info.Size(), match)
+	}
+	return nil
+}
+
 func run() error {
-	rootDir, err := parseFilesystem(os.Stdin)
+	if err := params.Parse(); err != nil {
+		return fmt.Errorf("failed to parse parameters: %v", err)
+	}
+
+	rootDir, warnings, err := parseTranscript(os.Stdin, !*lenient)
 
 	if err != nil {
 		return fmt.Errorf("failed to parse filesystem: %v", err)
 	}
 
-	fmt.Println("File system:", rootDir)
+	for _, warning := range warnings {
+		fmt.Fprintln(os.Stderr, "skipped", warning)
+	}
+
+	if *rewrite {
+		return writeTranscript(os.Stdout, rootDir)
+	}
+	if *glob != "" {
+		return printGlob(NewFS(rootDir), *glob)
+	}
+
+	if *plan {
+		return printPlan(rootDir)
+	}
+	if *du {
+		return writeDu(os.Stdout, rootDir)
+	}
+	if *tree {
+		return writeTree(os.Stdout, rootDir, TreeOptions{*depth, *below})
+	}
+
 	fmt.Println("Root size:", rootDir.Size())
 
-	part1 := part1(&rootDir)
+	part1 := part1(rootDir, *threshold)
 
 	fmt.Println("Part 1:", part1)
 
-	part2 := part2(&rootDir)
+	part2 := part2(rootDir, *diskSize, *required)
 
 	fmt.Println("Part 2:", part2)
 
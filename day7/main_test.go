@@ -0,0 +1,306 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+const example = `$ cd /
+$ ls
+dir a
+14848514 b.txt
+8504156 c.dat
+dir d
+$ cd a
+$ ls
+dir e
+29116 f
+2557 g
+62596 h.lst
+$ cd e
+$ ls
+584 i
+$ cd ..
+$ cd ..
+$ cd d
+$ ls
+4060174 j
+8033020 d.log
+5626152 d.ext
+7214296 k
+`
+
+func readExample(t *testing.T) *Directory {
+	root, err := parseFilesystem(strings.NewReader(example))
+	if err != nil {
+		t.Fatalf("failed to parse example: %v", err)
+	}
+	return root
+}
+
+func TestExample(t *testing.T) {
+	root := readExample(t)
+	if answer := part1(root, 100000); answer != 95437 {
+		t.Errorf("part 1 gave %d, expected 95437", answer)
+	}
+	if answer := part2(root, 70000000, 30000000); answer != 24933642 {
+		t.Errorf("part 2 gave %d, expected 24933642", answer)
+	}
+}
+
+func TestFS(t *testing.T) {
+	fsys := NewFS(readExample(t))
+	if err := fstest.TestFS(fsys, "a/e/i", "b.txt", "d/d.log"); err != nil {
+		t.Fatal(err)
+	}
+
+	sizes := map[string]int64{}
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		sizes[path] = info.Size()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to walk: %v", err)
+	}
+	for path, expected := range map[string]int64{".": 48381165, "a": 94853, "a/e": 584, "d/k": 7214296} {
+		if sizes[path] != expected {
+			t.Errorf("%s has size %d, expected %d", path, sizes[path], expected)
+		}
+	}
+
+	matches, err := fs.Glob(fsys, "*/*.l*")
+	if err != nil || !reflect.DeepEqual(matches, []string{"a/h.lst", "d/d.log"}) {
+		t.Errorf("globbing gave %v, %v", matches, err)
+	}
+
+	if _, err := fs.Stat(fsys, "a/missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected a missing file not to exist, got %v", err)
+	}
+}
+
+func TestReports(t *testing.T) {
+	for size, expected := range map[int]string{0: "0", 1023: "1023", 1024: "1.0K", 94853: "93K", 8033020: "7.7M", 1 << 40: "1.0T"} {
+		if got := humanSize(size); got != expected {
+			t.Errorf("%d is %s, expected %s", size, got, expected)
+		}
+	}
+
+	root := readExample(t)
+	var b strings.Builder
+	if err := writeDu(&b, root); err != nil {
+		t.Fatalf("failed to write du: %v", err)
+	}
+	expected := "584\t584\t/a/e\n94853\t93K\t/a\n24933642\t24M\t/d\n48381165\t46M\t/\n"
+	if b.String() != expected {
+		t.Errorf("du gave:\n%s\nexpected:\n%s", b.String(), expected)
+	}
+
+	// filtering like part 1 leaves just a, which has to be drawn under the
+	// root, and the depth stops at e
+	b.Reset()
+	if err := writeTree(&b, root, TreeOptions{Depth: 2, Below: 100000}); err != nil {
+		t.Fatalf("failed to write tree: %v", err)
+	}
+	expected = `/ (46M)
+└── a/ (93K)
+    ├── h.lst (61K)
+    ├── f (28K)
+    ├── g (2.5K)
+    └── e/ (584)
+`
+	if b.String() != expected {
+		t.Errorf("tree gave:\n%s\nexpected:\n%s", b.String(), expected)
+	}
+}
+
+func randomTree(rng *rand.Rand) (*Directory, []*Directory) {
+	root := newDirectory("/", nil)
+	dirs := []*Directory{root}
+	for i := rng.Intn(9); i > 0; i-- {
+		parent := dirs[rng.Intn(len(dirs))]
+		d := newDirectory(fmt.Sprint("d", len(dirs)), parent)
+		parent.directories[d.name] = d
+		dirs = append(dirs, d)
+	}
+	for _, d := range dirs {
+		for i := rng.Intn(3); i > 0; i-- {
+			d.files = append(d.files, File{fmt.Sprint("f", i), 1 + rng.Intn(100)})
+		}
+	}
+	return root, dirs
+}
+
+func isInside(d *Directory, ancestor *Directory) bool {
+	for ; d != nil; d = d.parent {
+		if d == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// bruteForcePlan tries every set of directories
+func bruteForcePlan(dirs []*Directory, need int, objective Objective, protected []string) (freed int, count int, found bool) {
+	p := &planner{objective: objective}
+	for set := 0; set < 1<<len(dirs); set++ {
+		chosen := []*Directory{}
+		for i, d := range dirs {
+			if set&(1<<i) != 0 {
+				chosen = append(chosen, d)
+			}
+		}
+
+		total, valid := 0, true
+		for _, d := range chosen {
+			total += d.Size()
+			valid = valid && !containsProtected(d, protected)
+			for _, other := range chosen {
+				valid = valid && (d == other || !isInside(d, other))
+			}
+		}
+
+		if valid && total >= need && p.beats(total, len(chosen)) {
+			p.found, p.bestFreed, p.best = true, total, make([]int, len(chosen))
+		}
+	}
+	return p.bestFreed, len(p.best), p.found
+}
+
+func TestPlanMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(48))
+	for n := 0; n < 300; n++ {
+		root, dirs := randomTree(rng)
+		need := 1 + rng.Intn(root.Size()+1)
+		protected := []string{}
+		if rng.Intn(2) == 0 {
+			protected = append(protected, dirs[rng.Intn(len(dirs))].Path())
+		}
+
+		for _, objective := range []Objective{LEAST_SPACE, FEWEST_DIRECTORIES} {
+			freed, count, found := bruteForcePlan(dirs, need, objective, protected)
+			plan, err := planDeletion(root, need, objective, protected, 1000000)
+			if (err == nil) != found {
+				t.Fatalf("planning to free %d protecting %v gave %v, but brute force found a plan: %v", need, protected, err, found)
+			}
+			if err != nil {
+				continue
+			}
+
+			if !plan.Optimal || plan.Freed != freed || len(plan.Directories) != count {
+				t.Errorf("objective %d planned %d from %d directories, expected %d from %d", objective, plan.Freed, len(plan.Directories), freed, count)
+			}
+		}
+	}
+
+	// only ever deleting one directory is part 2
+	root := readExample(t)
+	plan, err := planDeletion(root, root.Size()+30000000-70000000, FEWEST_DIRECTORIES, nil, 1000000)
+	if err != nil || len(plan.Directories) != 1 || plan.Freed != 24933642 {
+		t.Errorf("expected to delete just d, got %v, %v", plan, err)
+	}
+}
+
+func TestTranscriptRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(49))
+	for n := 0; n < 100; n++ {
+		root, _ := randomTree(rng)
+		var written strings.Builder
+		if err := writeTranscript(&written, root); err != nil {
+			t.Fatalf("failed to write transcript: %v", err)
+		}
+
+		parsed, err := parseFilesystem(strings.NewReader(written.String()))
+		if err != nil {
+			t.Fatalf("failed to parse written transcript:\n%s\n%v", written.String(), err)
+		}
+
+		var rewritten strings.Builder
+		if err := writeTranscript(&rewritten, parsed); err != nil {
+			t.Fatalf("failed to write transcript: %v", err)
+		}
+		if rewritten.String() != written.String() {
+			t.Fatalf("parsing then writing:\n%s\ngave:\n%s", written.String(), rewritten.String())
+		}
+	}
+}
+
+// the example again, but with GNU ls -l listings and cds which jump around
+const longExample = `$ cd /
+$ ls -la
+total 23352700
+drwxr-xr-x 4 elf elf     4096 Dec  7 12:00 .
+drwxr-xr-x 9 elf elf     4096 Dec  7 12:00 ..
+drwxr-xr-x 3 elf elf     4096 Dec  7 12:00 a
+-rw-r--r-- 1 elf elf 14848514 Dec  7 12:00 b.txt
+-rw-r--r-- 1 elf elf  8504156 Dec  7 12:00 c.dat
+drwxr-xr-x 2 elf elf     4096 Dec  7  2021 d
+lrwxrwxrwx 1 elf elf        1 Dec  7 12:00 link to a -> a
+crw-rw-rw- 1 root root   1,   3 Dec  7 12:00 null
+brw-rw---- 1 root disk 259,0 Dec  7 12:00 nvme0n1
+$ cd a
+$ ls
+dir e
+29116 f
+2557 g
+62596 h.lst
+$ cd e/../../d
+$ ls -l
+-rw-r--r-- 1 elf elf 4060174 Dec  7 12:00 j
+-rw-r--r-- 1 elf elf 8033020 Dec  7 12:00 d.log
+-rw-r--r-- 1 elf elf 5626152 Dec  7 12:00 d.ext
+-rw-r--r-- 1 elf elf 7214296 Dec  7 12:00 k
+$ cd /a/e
+$ ls
+584 i
+`
+
+func TestParserDialects(t *testing.T) {
+	root, err := parseFilesystem(strings.NewReader(longExample))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+
+	var got, expected strings.Builder
+	writeTranscript(&got, root)
+	writeTranscript(&expected, readExample(t))
+	if got.String() != expected.String() {
+		t.Errorf("parsed:\n%s\nexpected:\n%s", got.String(), expected.String())
+	}
+}
+
+func TestStrictAndLenient(t *testing.T) {
+	for _, c := range []struct {
+		transcript string
+		warnings   int
+	}{
+		{"$ ls\n1 a\n", 1},
+		{"$ cd /\n$ cd a\n", 1},
+		{"$ cd /\n$ cd ..\n", 1},
+		{"$ cd /\n$ pwd\n/\n$ ls\n1 a\n", 1},
+		{"$ cd /\n$ ls\nwhat is this\n", 1},
+		{"$ cd /\nstray output\n", 1},
+		{"$ cd /\n$ ls /a\n1 a\n", 1},
+	} {
+		if _, _, err := parseTranscript(strings.NewReader(c.transcript), true); err == nil {
+			t.Errorf("expected an error parsing %q strictly", c.transcript)
+		}
+
+		root, warnings, err := parseTranscript(strings.NewReader(c.transcript), false)
+		if err != nil || len(warnings) != c.warnings || root == nil {
+			t.Errorf("parsing %q leniently gave %v, %v, expected %d warnings", c.transcript, warnings, err, c.warnings)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Objective is what a deletion plan tries to keep down, with the other
+// breaking ties
+type Objective int
+
+const (
+	LEAST_SPACE Objective = iota
+	FEWEST_DIRECTORIES
+)
+
+func objectiveNamed(name string) (Objective, error) {
+	switch name {
+	case "space":
+		return LEAST_SPACE, nil
+	case "count":
+		return FEWEST_DIRECTORIES, nil
+	default:
+		return 0, fmt.Errorf("unknown objective %q", name)
+	}
+}
+
+// Plan is a set of directories to delete, none inside another
+type Plan struct {
+	Directories []*Directory
+	Freed       int
+	// whether the search finished, so there's no better plan. It's false if
+	// it gave up early, in which case this is the best it had found.
+	Optimal bool
+}
+
+// planner searches through the directories in pre-order, deciding whether to
+// delete each one. Deleting one skips everything inside it, which keeps the
+// plan from nesting.
+type planner struct {
+	dirs []*Directory
+	// skip[i] is the index just past everything inside dirs[i]
+	skip      []int
+	deletable []bool
+	// maxFree[i] is the most that can be freed from dirs[i:], to give up on
+	// anything which can't free enough
+	maxFree []int
+
+	need      int
+	objective Objective
+	maxVisits int
+	visits    int
+
+	chosen    []int
+	found     bool
+	best      []int
+	bestFreed int
+}
+
+func (p *planner) add(d *Directory, protected []string) {
+	i := len(p.dirs)
+	p.dirs = append(p.dirs, d)
+	p.skip = append(p.skip, 0)
+	p.deletable = append(p.deletable, !containsProtected(d, protected))
+
+	for _, sub := range d.subdirectories() {
+		p.add(sub, protected)
+	}
+	p.skip[i] = len(p.dirs)
+}
+
+func containsProtected(d *Directory, protected []string) bool {
+	path := d.Path()
+	for _, p := range protected {
+		if path == "/" || p == path || strings.HasPrefix(p, path+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// beats says whether a plan freeing this much with this many directories is
+// better than the best so far
+func (p *planner) beats(freed int, count int) bool {
+	if !p.found {
+		return true
+	}
+	if p.objective == FEWEST_DIRECTORIES && count != len(p.best) {
+		return count < len(p.best)
+	}
+	if freed != p.bestFreed {
+		return freed < p.bestFreed
+	}
+	return count < len(p.best)
+}
+
+// search returns false once it's visited too many states to carry on
+func (p *planner) search(i int, freed int) bool {
+	p.visits++
+	if p.visits > p.maxVisits {
+		return false
+	}
+
+	if freed >= p.need {
+		if p.beats(freed, len(p.chosen)) {
+			p.found, p.bestFreed = true, freed
+			p.best = append(p.best[:0], p.chosen...)
+		}
+		// deleting anything more can only make it worse
+		return true
+	}
+
+	if i == len(p.dirs) || freed+p.maxFree[i] < p.need {
+		return true
+	}
+	// anything from here deletes at least one more directory, and frees at
+	// least what's needed
+	if !p.beats(p.need, len(p.chosen)+1) {
+		return true
+	}
+
+	// deleting big directories first finds plans deleting few quickly, but
+	// trying what's inside them first gets closer to freeing exactly enough
+	if p.objective == FEWEST_DIRECTORIES {
+		return p.searchDeleting(i, freed) && p.search(i+1, freed)
+	}
+	return p.search(i+1, freed) && p.searchDeleting(i, freed)
+}
+
+func (p *planner) searchDeleting(i int, freed int) bool {
+	if !p.deletable[i] {
+		return true
+	}
+
+	p.chosen = append(p.chosen, i)
+	ok := p.search(p.skip[i], freed+p.dirs[i].Size())
+	p.chosen = p.chosen[:len(p.chosen)-1]
+	return ok
+}
+
+// planDeletion finds directories to delete which free at least need, none
+// inside another and none holding a protected path, keeping the objective
+// down. The search is a branch and bound, which can blow up on big trees, so
+// it gives up after maxVisits states with the best plan so far.
+func planDeletion(root *Directory, need int, objective Objective, protected []string, maxVisits int) (Plan, error) {
+	if need <= 0 {
+		return Plan{Directories: []*Directory{}, Optimal: true}, nil
+	}
+
+	p := &planner{need: need, objective: objective, maxVisits: maxVisits}
+	p.add(root, protected)
+
+	p.maxFree = make([]int, len(p.dirs)+1)
+	for i := len(p.dirs) - 1; i >= 0; i-- {
+		p.maxFree[i] = p.maxFree[i+1]
+		if p.deletable[i] && p.dirs[i].Size()+p.maxFree[p.skip[i]] > p.maxFree[i] {
+			p.maxFree[i] = p.dirs[i].Size() + p.maxFree[p.skip[i]]
+		}
+	}
+	if p.maxFree[0] < need {
+		return Plan{}, fmt.Errorf("can free at most %d without deleting anything protected, not %d", p.maxFree[0], need)
+	}
+
+	optimal := p.search(0, 0)
+	if !p.found {
+		return Plan{}, fmt.Errorf("gave up after visiting %d states without finding a plan", maxVisits)
+	}
+
+	plan := Plan{Directories: []*Directory{}, Freed: p.bestFreed, Optimal: optimal}
+	for _, i := range p.best {
+		plan.Directories = append(plan.Directories, p.dirs[i])
+	}
+	return plan, nil
+}
+
+// checkProtected makes sure every protected path is in the tree, as a typo
+// would otherwise protect nothing
+func checkProtected(fsys FS, protected []string) error {
+	for _, path := range protected {
+		name := strings.TrimPrefix(path, "/")
+		if name == "" {
+			name = "."
+		}
+		if !strings.HasPrefix(path, "/") || strings.HasSuffix(name, "/") {
+			return fmt.Errorf("protected path %q must be absolute, without a trailing /", path)
+		}
+		if _, err := fsys.Stat(name); err != nil {
+			return fmt.Errorf("protected path %q isn't in the file system", path)
+		}
+	}
+	return nil
+}
+
+func writePlan(w io.Writer, plan Plan) error {
+	paths := []string{}
+	for _, d := range plan.Directories {
+		paths = append(paths, d.Path())
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		if _, err := fmt.Fprintln(w, path); err != nil {
+			return err
+		}
+	}
+
+	note := ""
+	if !plan.Optimal {
+		note = " (gave up searching, so there may be a better plan)"
+	}
+	_, err := fmt.Fprintf(w, "Frees %d (%s) from %d directories%s\n", plan.Freed, humanSize(plan.Freed), len(plan.Directories), note)
+	return err
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Path is the directory's absolute path, as cd would take it
+func (d *Directory) Path() string {
+	if d.parent == nil {
+		return "/"
+	}
+	if d.parent.parent == nil {
+		return "/" + d.name
+	}
+	return d.parent.Path() + "/" + d.name
+}
+
+// subdirectories are sorted by name, so reports come out the same every time
+func (d *Directory) subdirectories() []*Directory {
+	dirs := make([]*Directory, 0, len(d.directories))
+	for _, sub := range d.directories {
+		dirs = append(dirs, sub)
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		return dirs[i].name < dirs[j].name
+	})
+	return dirs
+}
+
+// humanSize gives a size in the largest binary unit it fills, like du -h
+func humanSize(size int) string {
+	units := []string{"", "K", "M", "G", "T", "P"}
+	value, unit := float64(size), 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+
+	switch {
+	case unit == 0:
+		return fmt.Sprint(size)
+	case value < 10:
+		return fmt.Sprintf("%.1f%s", value, units[unit])
+	default:
+		return fmt.Sprintf("%.0f%s", value, units[unit])
+	}
+}
+
+// writeDu lists every directory with its size, children before their parent,
+// like du
+func writeDu(w io.Writer, d *Directory) error {
+	for _, sub := range d.subdirectories() {
+		if err := writeDu(w, sub); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d\t%s\t%s\n", d.Size(), humanSize(d.Size()), d.Path())
+	return err
+}
+
+// TreeOptions limit what the tree shows. Anything not shown because of the
+// filter still has the directories leading to it shown, so it can be found.
+type TreeOptions struct {
+	// how many levels below the root to show, or 0 for all of them
+	Depth int
+	// only show what's smaller than this, like part 1, or 0 for everything
+	Below int
+}
+
+type treeEntry struct {
+	name string
+	size int
+	// nil for files
+	dir *Directory
+}
+
+func (o TreeOptions) matches(size int) bool {
+	return o.Below == 0 || size < o.Below
+}
+
+// collect finds what the tree shows in every directory, largest first. It
+// works bottom-up, so whether a directory has anything to show is only
+// worked out once, however deep it is.
+func (o TreeOptions) collect(d *Directory, depth int, shown map[*Directory][]treeEntry) {
+	if o.Depth > 0 && depth >= o.Depth {
+		return
+	}
+
+	entries := []treeEntry{}
+	for _, f := range d.files {
+		if o.matches(f.Size()) {
+			entries = append(entries, treeEntry{f.name, f.Size(), nil})
+		}
+	}
+	for _, sub := range d.directories {
+		o.collect(sub, depth+1, shown)
+		if o.matches(sub.Size()) || len(shown[sub]) > 0 {
+			entries = append(entries, treeEntry{sub.name, sub.Size(), sub})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].size != entries[j].size {
+			return entries[i].size > entries[j].size
+		}
+		return entries[i].name < entries[j].name
+	})
+	shown[d] = entries
+}
+
+// writeTree draws the tree like the tree command, with the sizes of
+// everything and the largest first
+func writeTree(w io.Writer, root *Directory, options TreeOptions) error {
+	if _, err := fmt.Fprintf(w, "%s (%s)\n", root.Path(), humanSize(root.Size())); err != nil {
+		return err
+	}
+
+	shown := map[*Directory][]treeEntry{}
+	options.collect(root, 0, shown)
+	return writeEntries(w, shown, root, "")
+}
+
+func writeEntries(w io.Writer, shown map[*Directory][]treeEntry, d *Directory, prefix string) error {
+	entries := shown[d]
+	for i, e := range entries {
+		branch, indent := "├── ", "│   "
+		if i == len(entries)-1 {
+			branch, indent = "└── ", "    "
+		}
+
+		name := e.name
+		if e.dir != nil {
+			name += "/"
+		}
+		if _, err := fmt.Fprintf(w, "%s%s%s (%s)\n", prefix, branch, name, humanSize(e.size)); err != nil {
+			return err
+		}
+
+		if e.dir != nil {
+			if err := writeEntries(w, shown, e.dir, prefix+indent); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
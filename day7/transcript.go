@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	totalPattern = regexp.MustCompile(`^total \d+$`)
+	filePattern  = regexp.MustCompile(`^(\d+) (.+)$`)
+	// GNU ls -l, like "-rw-r--r-- 1 user group 2557 Dec  7 12:00 g". The
+	// date takes three fields, and the name is everything after it. Devices
+	// have "major, minor" numbers in place of the size.
+	longPattern = regexp.MustCompile(`^([-dlbcps])[-rwxsStT]{9}[.+@]?\s+\d+\s+\S+\s+\S+\s+(\d+|\d+,\s*\d+)\s+\S+\s+\S+\s+\S+ (.+)$`)
+)
+
+// parser reads a terminal transcript. Strictly, anything it doesn't
+// understand is an error. Leniently, it carries on as best it can, noting
+// what it had to skip or guess in warnings.
+type parser struct {
+	strict   bool
+	warnings []string
+	line     int
+
+	root, cwd *Directory
+	// the directory being listed by ls, if its output is being read
+	listing *Directory
+	// whether the output being read is from a command which isn't understood
+	skipping bool
+}
+
+// problem is an error when strict, and a warning otherwise
+func (p *parser) problem(format string, args ...any) error {
+	message := fmt.Sprintf("line %d: %s", p.line, fmt.Sprintf(format, args...))
+	if p.strict {
+		return errors.New(message)
+	}
+	p.warnings = append(p.warnings, message)
+	return nil
+}
+
+func (p *parser) parseLine(line string) error {
+	if strings.HasPrefix(line, "$ ") {
+		p.listing, p.skipping = nil, false
+		return p.parseCommand(strings.TrimPrefix(line, "$ "))
+	}
+
+	switch {
+	case p.listing != nil:
+		return p.parseLs(line)
+	case p.skipping:
+		return nil
+	default:
+		return p.problem("output %q isn't from ls", line)
+	}
+}
+
+func (p *parser) parseCommand(command string) error {
+	if strings.HasPrefix(command, "cd ") {
+		return p.parseCd(strings.TrimPrefix(command, "cd "))
+	}
+
+	parts := strings.Fields(command)
+	if len(parts) > 0 && parts[0] == "ls" {
+		// flags like -l are fine, but listing somewhere else isn't
+		for _, arg := range parts[1:] {
+			if !strings.HasPrefix(arg, "-") {
+				p.skipping = true
+				return p.problem("can't tell where ls %s is listing", arg)
+			}
+		}
+
+		cwd, err := p.current("ls")
+		if err != nil {
+			return err
+		}
+		// listing again replaces the files, but the directories stay, as
+		// they might already have been explored
+		p.listing = cwd
+		cwd.files = []File{}
+		return nil
+	}
+
+	p.skipping = true
+	return p.problem("unknown command %q", command)
+}
+
+// current is the working directory, which is only known after the first
+// absolute cd. Leniently, the transcript is assumed to start at /.
+func (p *parser) current(command string) (*Directory, error) {
+	if p.cwd == nil {
+		if err := p.problem("%s before changing to /", command); err != nil {
+			return nil, err
+		}
+		p.cwd = p.root
+	}
+	return p.cwd, nil
+}
+
+// parseCd follows the target a segment at a time, so it can be absolute and
+// have several segments, like /a/e or ../d
+func (p *parser) parseCd(target string) error {
+	dir := p.root
+	if !strings.HasPrefix(target, "/") {
+		cwd, err := p.current("cd " + target)
+		if err != nil {
+			return err
+		}
+		dir = cwd
+	}
+
+	for _, segment := range strings.Split(target, "/") {
+		switch segment {
+		case "", ".":
+			continue
+		case "..":
+			if dir.parent == nil {
+				// a shell stays at / too
+				if err := p.problem("cd %s goes above /", target); err != nil {
+					return err
+				}
+				continue
+			}
+			dir = dir.parent
+		default:
+			sub, exists := dir.directories[segment]
+			if !exists {
+				if err := p.problem("cd %s goes into %s, which hasn't been listed", target, segment); err != nil {
+					return err
+				}
+				sub = newDirectory(segment, dir)
+				dir.directories[segment] = sub
+			}
+			dir = sub
+		}
+	}
+
+	p.cwd = dir
+	return nil
+}
+
+// parseLs reads a line of ls output, in either the puzzle's format or GNU's
+// ls -l. Symlinks and special files don't take up space of their own, so
+// they're left out.
+func (p *parser) parseLs(line string) error {
+	if strings.HasPrefix(line, "dir ") {
+		p.addDirectory(strings.TrimPrefix(line, "dir "))
+		return nil
+	}
+	if match := filePattern.FindStringSubmatch(line); match != nil {
+		return p.addFile(match[2], match[1])
+	}
+	if totalPattern.MatchString(line) {
+		return nil
+	}
+
+	match := longPattern.FindStringSubmatch(line)
+	if match == nil {
+		return p.problem("can't parse ls output %q", line)
+	}
+
+	switch match[1] {
+	case "d":
+		if match[3] != "." && match[3] != ".." {
+			p.addDirectory(match[3])
+		}
+	case "-":
+		return p.addFile(match[3], match[2])
+	}
+	return nil
+}
+
+func (p *parser) addDirectory(name string) {
+	if _, exists := p.listing.directories[name]; !exists {
+		p.listing.directories[name] = newDirectory(name, p.listing)
+	}
+}
+
+func (p *parser) addFile(name string, size string) error {
+	parsed, err := strconv.Atoi(size)
+	if err != nil {
+		return p.problem("failed to parse file %s size: %v", name, err)
+	}
+	p.listing.files = append(p.listing.files, File{name, parsed})
+	return nil
+}
+
+// parseTranscript builds the directory tree from a transcript, along with
+// anything it had to skip over when lenient
+func parseTranscript(r io.Reader, strict bool) (*Directory, []string, error) {
+	root := newDirectory("/", nil)
+	p := &parser{strict: strict, root: root}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		p.line++
+		if err := p.parseLine(scanner.Text()); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read transcript: %v", err)
+	}
+
+	return root, p.warnings, nil
+}
+
+// parseFilesystem parses a transcript strictly
+func parseFilesystem(r io.Reader) (*Directory, error) {
+	root, _, err := parseTranscript(r, true)
+	return root, err
+}
+
+// writeTranscript writes a session which explores the whole tree, in the
+// puzzle's format, listing each directory then going into its
+// subdirectories in order
+func writeTranscript(w io.Writer, root *Directory) error {
+	if _, err := fmt.Fprintln(w, "$ cd /"); err != nil {
+		return err
+	}
+	return writeListing(w, root)
+}
+
+func writeListing(w io.Writer, d *Directory) error {
+	subdirectories := d.subdirectories()
+
+	lines := []string{"$ ls"}
+	for _, sub := range subdirectories {
+		lines = append(lines, "dir "+sub.name)
+	}
+	for _, f := range d.files {
+		lines = append(lines, fmt.Sprintf("%d %s", f.size, f.name))
+	}
+	if _, err := fmt.Fprintln(w, strings.Join(lines, "\n")); err != nil {
+		return err
+	}
+
+	for _, sub := range subdirectories {
+		if _, err := fmt.Fprintln(w, "$ cd", sub.name); err != nil {
+			return err
+		}
+		if err := writeListing(w, sub); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, "$ cd .."); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"io"
 	"os"
+
+	"github.com/WJBarnes456/aoc-2022/containers"
 )
 
 type Direction int
 
 const (
-	North = iota
+	North Direction = iota
 	East
 	South
 	West
@@ -107,7 +109,7 @@ func getVisibility(heights [][]int) ([][]Tree, error) {
 	}
 
 	// south pass
-	southTallestTrees := make([]*Tree, gridHeight)
+	southTallestTrees := make([]*Tree, gridWidth)
 	for i := gridHeight - 1; i >= 0; i-- {
 		for j := 0; j < gridWidth; j++ {
 			setVisibility(trees, southTallestTrees, i, j, South)
@@ -137,55 +139,105 @@ func part1(trees [][]Tree) int {
 	return total
 }
 
-func part2(trees [][]Tree) int {
-	bestScore := 0
+// lookAlong works out how far each tree in a line can see back towards the
+// start of it, with a stack of the trees which haven't been blocked yet by
+// a tree at least as tall. Each tree is pushed and popped at most once, so
+// the whole line takes linear time.
+func lookAlong(length int, height func(k int) int, setDistance func(k int, distance int)) {
+	var unblocked containers.Stack[int]
+	for k := 0; k < length; k++ {
+		blocker, ok := unblocked.TryPeek()
+		for ok && height(blocker) < height(k) {
+			unblocked.TryPop()
+			blocker, ok = unblocked.TryPeek()
+		}
+
+		// with nothing tall enough in the way, the tree sees to the edge
+		if ok {
+			setDistance(k, k-blocker)
+		} else {
+			setDistance(k, k)
+		}
+		unblocked.Push(k)
+	}
+}
+
+// viewingDistances gives how many trees each tree can see looking in the
+// direction, up to and including the first one at least as tall
+func viewingDistances(trees [][]Tree, d Direction) ([][]int, error) {
 	gridHeight, gridWidth := len(trees), len(trees[0])
+	distances := make([][]int, gridHeight)
+	for i := range distances {
+		distances[i] = make([]int, gridWidth)
+	}
 
-	for i, row := range trees {
-		for j, tree := range row {
-			// ignore edges
-			if i == 0 || j == 0 || i == gridHeight-1 || j == gridWidth-1 {
-				continue
+	// each line starts at the edge the trees are looking towards
+	switch d {
+	case North, South:
+		for j := 0; j < gridWidth; j++ {
+			row := func(k int) int { return k }
+			if d == South {
+				row = func(k int) int { return gridHeight - 1 - k }
 			}
-
-			// do a pass in each direction
-			var north, east, south, west int
-			for north = 1; north < i; north++ {
-				northTree := trees[i-north][j]
-				if northTree.height >= tree.height {
-					break
-				}
+			lookAlong(gridHeight,
+				func(k int) int { return trees[row(k)][j].height },
+				func(k int, distance int) { distances[row(k)][j] = distance })
+		}
+	case East, West:
+		for i := 0; i < gridHeight; i++ {
+			column := func(k int) int { return k }
+			if d == East {
+				column = func(k int) int { return gridWidth - 1 - k }
 			}
+			lookAlong(gridWidth,
+				func(k int) int { return trees[i][column(k)].height },
+				func(k int, distance int) { distances[i][column(k)] = distance })
+		}
+	default:
+		return nil, fmt.Errorf("looking in unknown direction %d", d)
+	}
 
-			for east = 1; east < gridWidth-j-1; east++ {
-				eastTree := trees[i][j+east]
-				if eastTree.height >= tree.height {
-					break
-				}
-			}
+	return distances, nil
+}
 
-			for south = 1; south < gridHeight-i-1; south++ {
-				southTree := trees[i+south][j]
-				if southTree.height >= tree.height {
-					break
-				}
-			}
+// scenicScores multiplies together every tree's viewing distances, which is
+// 0 for the trees on the edge
+func scenicScores(trees [][]Tree) ([][]int, error) {
+	scores := [][]int{}
+	for _, d := range []Direction{North, East, South, West} {
+		distances, err := viewingDistances(trees, d)
+		if err != nil {
+			return nil, err
+		}
 
-			for west = 1; west < j; west++ {
-				westTree := trees[i][j-west]
-				if westTree.height >= tree.height {
-					break
-				}
+		if len(scores) == 0 {
+			scores = distances
+			continue
+		}
+		for i, row := range distances {
+			for j, distance := range row {
+				scores[i][j] *= distance
 			}
+		}
+	}
+	return scores, nil
+}
+
+func part2(trees [][]Tree) (int, error) {
+	scores, err := scenicScores(trees)
+	if err != nil {
+		return 0, err
+	}
 
-			score := north * east * south * west
-			fmt.Println(tree, north, east, south, west)
+	bestScore := 0
+	for _, row := range scores {
+		for _, score := range row {
 			if score > bestScore {
 				bestScore = score
 			}
 		}
 	}
-	return bestScore
+	return bestScore, nil
 }
 
 func run() error {
@@ -195,21 +247,21 @@ func run() error {
 		return fmt.Errorf("failed to parse input: %v", err)
 	}
 
-	fmt.Println(grid)
-
 	trees, err := getVisibility(grid)
 
 	if err != nil {
 		return fmt.Errorf("failed to get visibility: %v", err)
 	}
 
-	for _, row := range trees {
-		fmt.Println(row)
-	}
-
 	fmt.Println("Part 1:", part1(trees))
 
-	fmt.Println("Part 2:", part2(trees))
+	part2, err := part2(trees)
+
+	if err != nil {
+		return fmt.Errorf("failed to score trees: %v", err)
+	}
+
+	fmt.Println("Part 2:", part2)
 
 	return nil
 }
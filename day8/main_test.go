@@ -0,0 +1,98 @@
+package main
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/WJBarnes456/aoc-2022/difftest"
+)
+
+const example = `30373
+25512
+65332
+33549
+35390
+`
+
+func readTrees(t *testing.T, input string) [][]Tree {
+	heights, err := parseInput(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("failed to parse input: %v", err)
+	}
+	trees, err := getVisibility(heights)
+	if err != nil {
+		t.Fatalf("failed to get visibility: %v", err)
+	}
+	return trees
+}
+
+func TestExample(t *testing.T) {
+	trees := readTrees(t, example)
+	if answer := part1(trees); answer != 21 {
+		t.Errorf("part 1 gave %d, expected 21", answer)
+	}
+
+	scores, err := scenicScores(trees)
+	if err != nil {
+		t.Fatalf("failed to score trees: %v", err)
+	}
+	// the example's two worked trees
+	if scores[1][2] != 4 || scores[3][2] != 8 {
+		t.Errorf("scored the worked trees %d and %d, expected 4 and 8", scores[1][2], scores[3][2])
+	}
+}
+
+// walk out from every tree in each direction, as part 2 first did
+func referenceScenicScores(heights [][]int) [][]int {
+	scores := make([][]int, len(heights))
+	for i, row := range heights {
+		scores[i] = make([]int, len(row))
+		for j, height := range row {
+			score := 1
+			for _, step := range [][2]int{{-1, 0}, {0, 1}, {1, 0}, {0, -1}} {
+				distance := 0
+				for y, x := i+step[0], j+step[1]; y >= 0 && y < len(heights) && x >= 0 && x < len(row); y, x = y+step[0], x+step[1] {
+					distance++
+					if heights[y][x] >= height {
+						break
+					}
+				}
+				score *= distance
+			}
+			scores[i][j] = score
+		}
+	}
+	return scores
+}
+
+func stackScenicScores(heights [][]int) [][]int {
+	trees, err := getVisibility(heights)
+	if err != nil {
+		return nil
+	}
+	scores, err := scenicScores(trees)
+	if err != nil {
+		return nil
+	}
+	return scores
+}
+
+func TestScenicScoresMatchReference(t *testing.T) {
+	h := difftest.Harness[[][]int, [][]int]{}
+	h.Register("monotonic stacks", stackScenicScores)
+	h.Register("reference", referenceScenicScores)
+
+	h.Generate(t, 8, 500, func(rng *rand.Rand) [][]int {
+		// few heights makes ties, where the view is blocked, common
+		heights := make([][]int, 1+rng.Intn(8))
+		width := 1 + rng.Intn(8)
+		for i := range heights {
+			heights[i] = make([]int, width)
+			for j := range heights[i] {
+				heights[i][j] = rng.Intn(4)
+			}
+		}
+		return heights
+	})
+}
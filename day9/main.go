@@ -4,6 +4,9 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+
+	"github.com/WJBarnes456/aoc-2022/frames"
+	"github.com/WJBarnes456/aoc-2022/params"
 )
 
 type Location struct {
@@ -100,7 +103,45 @@ func getMove(line string) (*Instruction, error) {
 
 }
 
-func simulate(moves []*Instruction, ropeLength int) (int, error) {
+// The rendered view follows the head, as the rope can wander a long way
+const VIEW_WIDTH, VIEW_HEIGHT = 41, 21
+
+// Draws the area around the head, with y increasing upwards as in the puzzle
+func render(rope []*Location, visited map[Location]struct{}) []string {
+	head := rope[0]
+	labels := map[Location]byte{}
+	// draw from the tail forwards so that earlier knots cover later ones
+	for i := len(rope) - 1; i >= 0; i-- {
+		label := byte('0' + i)
+		if i == 0 {
+			label = 'H'
+		} else if len(rope) == 2 {
+			label = 'T'
+		}
+		labels[*rope[i]] = label
+	}
+
+	lines := make([]string, 0, VIEW_HEIGHT)
+	for y := head.Y + VIEW_HEIGHT/2; y > head.Y-VIEW_HEIGHT/2-1; y-- {
+		line := make([]byte, 0, VIEW_WIDTH)
+		for x := head.X - VIEW_WIDTH/2; x < head.X+VIEW_WIDTH/2+1; x++ {
+			loc := Location{x, y}
+			if label, exists := labels[loc]; exists {
+				line = append(line, label)
+			} else if loc == (Location{0, 0}) {
+				line = append(line, 's')
+			} else if _, wasVisited := visited[loc]; wasVisited {
+				line = append(line, '#')
+			} else {
+				line = append(line, '.')
+			}
+		}
+		lines = append(lines, string(line))
+	}
+	return lines
+}
+
+func simulate(moves []*Instruction, ropeLength int, fw *frames.Writer, title string) (int, error) {
 	rope := make([]*Location, ropeLength)
 	for i := range rope {
 		rope[i] = &Location{0, 0}
@@ -111,7 +152,7 @@ func simulate(moves []*Instruction, ropeLength int) (int, error) {
 	visitedLocations := make(map[Location]struct{})
 	visitedLocations[*tail] = struct{}{}
 
-	for _, m := range moves {
+	for moveNo, m := range moves {
 		for i := 0; i < m.iterations; i++ {
 			rope[0].X += m.deltaX
 			rope[0].Y += m.deltaY
@@ -123,6 +164,13 @@ func simulate(moves []*Instruction, ropeLength int) (int, error) {
 			}
 
 			visitedLocations[*tail] = struct{}{}
+
+			if fw != nil {
+				frameTitle := fmt.Sprintf("%s: move %d/%d, step %d/%d, %d visited", title, moveNo+1, len(moves), i+1, m.iterations, len(visitedLocations))
+				if err := fw.Emit(frameTitle, render(rope, visitedLocations)); err != nil {
+					return 0, fmt.Errorf("failed to emit frame: %v", err)
+				}
+			}
 		}
 	}
 
@@ -130,6 +178,11 @@ func simulate(moves []*Instruction, ropeLength int) (int, error) {
 }
 
 func run() error {
+	if err := params.Parse(); err != nil {
+		return fmt.Errorf("failed to parse parameters: %v", err)
+	}
+	fw := frames.New()
+
 	scanner := bufio.NewScanner(os.Stdin)
 
 	moves := []*Instruction{}
@@ -141,14 +194,14 @@ func run() error {
 		moves = append(moves, move)
 	}
 
-	part1, err := simulate(moves, 2)
+	part1, err := simulate(moves, 2, fw, "Part 1")
 
 	if err != nil {
 		return fmt.Errorf("failed to solve part1: %v", err)
 	}
 	fmt.Println("Part 1:", part1)
 
-	part2, err := simulate(moves, 10)
+	part2, err := simulate(moves, 10, fw, "Part 2")
 	if err != nil {
 		return fmt.Errorf("failed to solve part2: %v", err)
 	}
@@ -0,0 +1,76 @@
+package difftest
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Matches the answer lines the days print, e.g. "Part 1: 24000", and the
+// odd ones out like "part 1: 33" or "Part 1 13140"
+var answerLine = regexp.MustCompile(`(?i)^part ?(\d)\b:?\s*(.*)$`)
+
+// ParseAnswers picks the answers out of a day's output, keyed by part
+func ParseAnswers(output string) map[int]string {
+	answers := map[int]string{}
+	for _, line := range strings.Split(output, "\n") {
+		match := answerLine.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		part, _ := strconv.Atoi(match[1])
+		answers[part] = match[2]
+	}
+	return answers
+}
+
+// Binary builds the day in the given package directory and returns a
+// strategy which runs it on an input, for comparing whole solutions that
+// live in separate packages (like day16 and day16_2).
+//
+// Days read their input either from stdin or from input.txt in their
+// working directory, so the input is provided both ways. A day which fails
+// makes the strategy panic, which the harness reports as a disagreement.
+func Binary(t testing.TB, pkg string, args ...string) func(string) map[int]string {
+	t.Helper()
+
+	dir, err := filepath.Abs(pkg)
+	if err != nil {
+		t.Fatalf("failed to find %s: %v", pkg, err)
+	}
+
+	binary := filepath.Join(t.TempDir(), filepath.Base(dir))
+	build := exec.Command("go", "build", "-o", binary, ".")
+	build.Dir = dir
+	if output, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("failed to build %s: %v\n%s", pkg, err, output)
+	}
+
+	return func(input string) map[int]string {
+		workDir, err := os.MkdirTemp("", "difftest")
+		if err != nil {
+			panic(err)
+		}
+		defer os.RemoveAll(workDir)
+
+		if err := os.WriteFile(filepath.Join(workDir, "input.txt"), []byte(input), 0o644); err != nil {
+			panic(err)
+		}
+
+		cmd := exec.Command(binary, args...)
+		cmd.Dir = workDir
+		cmd.Stdin = strings.NewReader(input)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		output, err := cmd.Output()
+		if err != nil {
+			panic(err.Error() + ": " + strings.TrimSpace(stderr.String()))
+		}
+		return ParseAnswers(string(output))
+	}
+}
@@ -0,0 +1,172 @@
+// Package difftest checks alternative implementations of the same solver
+// against each other, e.g. an optimised solution against a naive reference.
+//
+// Strategies are registered on a Harness, then run on fixtures or on
+// generated inputs from inside a normal go test. When they disagree, the
+// harness shrinks the input (if it knows how) and fails the test with the
+// smallest input it found that still shows the disagreement.
+package difftest
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type strategy[I, O any] struct {
+	name  string
+	solve func(I) O
+}
+
+type Harness[I, O any] struct {
+	strategies []strategy[I, O]
+
+	// Shrink, if set, proposes smaller variants of an input. The harness
+	// keeps moving to the first variant that still disagrees.
+	Shrink func(I) []I
+
+	// Format, if set, is used to print inputs in failure reports
+	Format func(I) string
+}
+
+// Register adds an implementation. The first one registered is treated as
+// the reference in reports, but any difference between any of them fails.
+func (h *Harness[I, O]) Register(name string, solve func(I) O) {
+	h.strategies = append(h.strategies, strategy[I, O]{name, solve})
+}
+
+// Result is what one strategy produced: its output, or what it panicked with
+type Result[O any] struct {
+	Name   string
+	Output O
+	Panic  any
+}
+
+func (r Result[O]) String() string {
+	if r.Panic != nil {
+		return fmt.Sprintf("%s: panicked: %v", r.Name, r.Panic)
+	}
+	return fmt.Sprintf("%s: %v", r.Name, r.Output)
+}
+
+type Disagreement[I, O any] struct {
+	Input   I
+	Results []Result[O]
+}
+
+func run[I, O any](s strategy[I, O], input I) (result Result[O]) {
+	result.Name = s.name
+	defer func() {
+		result.Panic = recover()
+	}()
+	result.Output = s.solve(input)
+	return result
+}
+
+func agree[O any](a Result[O], b Result[O]) bool {
+	if a.Panic != nil || b.Panic != nil {
+		return false
+	}
+	return reflect.DeepEqual(a.Output, b.Output)
+}
+
+// Compare runs every strategy on the input, returning nil if they all agree
+func (h *Harness[I, O]) Compare(input I) *Disagreement[I, O] {
+	if len(h.strategies) == 0 {
+		return nil
+	}
+
+	results := make([]Result[O], 0, len(h.strategies))
+	for _, s := range h.strategies {
+		results = append(results, run(s, input))
+	}
+
+	for _, result := range results[1:] {
+		if !agree(results[0], result) {
+			return &Disagreement[I, O]{input, results}
+		}
+	}
+
+	// a lone strategy still fails if it panics
+	if results[0].Panic != nil {
+		return &Disagreement[I, O]{input, results}
+	}
+	return nil
+}
+
+// Minimise shrinks a disagreement for as long as a smaller input still
+// disagrees. It's greedy, so the result is locally rather than globally
+// minimal.
+func (h *Harness[I, O]) Minimise(d *Disagreement[I, O]) *Disagreement[I, O] {
+	if h.Shrink == nil {
+		return d
+	}
+
+	for shrunk := true; shrunk; {
+		shrunk = false
+		for _, candidate := range h.Shrink(d.Input) {
+			if smaller := h.Compare(candidate); smaller != nil {
+				d, shrunk = smaller, true
+				break
+			}
+		}
+	}
+	return d
+}
+
+func (h *Harness[I, O]) report(d *Disagreement[I, O]) string {
+	var input string
+	if h.Format != nil {
+		input = h.Format(d.Input)
+	} else {
+		input = fmt.Sprintf("%v", d.Input)
+	}
+
+	lines := []string{"strategies disagree on input:", input, "results:"}
+	for _, result := range d.Results {
+		lines = append(lines, "  "+result.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Check compares the strategies on each fixture, failing the test for any
+// they disagree on
+func (h *Harness[I, O]) Check(t testing.TB, fixtures ...I) {
+	t.Helper()
+	for _, fixture := range fixtures {
+		if d := h.Compare(fixture); d != nil {
+			t.Error(h.report(h.Minimise(d)))
+		}
+	}
+}
+
+// Generate compares the strategies on n generated inputs, stopping at the
+// first disagreement. The seed is in the failure message, so failures can
+// be reproduced.
+func (h *Harness[I, O]) Generate(t testing.TB, seed int64, n int, generate func(*rand.Rand) I) {
+	t.Helper()
+	rng := rand.New(rand.NewSource(seed))
+	for i := 0; i < n; i++ {
+		if d := h.Compare(generate(rng)); d != nil {
+			t.Errorf("generated input %d (seed %d): %s", i, seed, h.report(h.Minimise(d)))
+			return
+		}
+	}
+}
+
+// ShrinkSlice proposes smaller slices: first with large chunks removed,
+// then with single elements removed. It never proposes an empty slice.
+func ShrinkSlice[T any](s []T) [][]T {
+	candidates := [][]T{}
+	for chunk := len(s) / 2; chunk >= 1; chunk /= 2 {
+		for start := 0; start+chunk <= len(s); start += chunk {
+			candidate := make([]T, 0, len(s)-chunk)
+			candidate = append(candidate, s[:start]...)
+			candidate = append(candidate, s[start+chunk:]...)
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates
+}
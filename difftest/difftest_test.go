@@ -0,0 +1,62 @@
+package difftest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func sum(values []int) int {
+	total := 0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+// ignores negative values, so only disagrees with sum when there's one
+func buggySum(values []int) int {
+	total := 0
+	for _, v := range values {
+		if v > 0 {
+			total += v
+		}
+	}
+	return total
+}
+
+func TestMinimiseFindsSmallestDisagreement(t *testing.T) {
+	h := Harness[[]int, int]{Shrink: ShrinkSlice[int]}
+	h.Register("sum", sum)
+	h.Register("buggy", buggySum)
+
+	if d := h.Compare([]int{1, 2, 3}); d != nil {
+		t.Fatalf("strategies should agree on positive values, got %v", d.Results)
+	}
+
+	d := h.Compare([]int{4, 8, -1, 3, 5, 9})
+	if d == nil {
+		t.Fatalf("strategies should disagree on a negative value")
+	}
+
+	if minimal := h.Minimise(d); !reflect.DeepEqual(minimal.Input, []int{-1}) {
+		t.Errorf("expected to shrink to [-1], got %v", minimal.Input)
+	}
+}
+
+func TestPanicsAreDisagreements(t *testing.T) {
+	h := Harness[[]int, int]{}
+	h.Register("first", func(values []int) int { return values[0] })
+
+	d := h.Compare([]int{})
+	if d == nil || d.Results[0].Panic == nil {
+		t.Fatalf("expected a panic to be reported, got %v", d)
+	}
+}
+
+func TestParseAnswers(t *testing.T) {
+	output := "debug output\nPart 1: 24000\npart 2: 33\n"
+	expected := map[int]string{1: "24000", 2: "33"}
+	if answers := ParseAnswers(output); !reflect.DeepEqual(answers, expected) {
+		t.Errorf("expected %v, got %v", expected, answers)
+	}
+}
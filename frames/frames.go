@@ -0,0 +1,112 @@
+// Package frames is how the simulation days stream their state to the
+// dashboard. When a day is run with -frames, it writes each step of the
+// simulation to stdout as a frame: a header line holding the frame's title,
+// the rendered state, then a line holding just a form feed.
+//
+// Anything else the day prints (like the answers) is left as plain lines in
+// between frames, so a reader can still pick those out.
+package frames
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	// Header starts the title line of a frame
+	Header = "\x1e"
+	// Separator ends a frame
+	Separator = "\f"
+)
+
+var enabled = flag.Bool("frames", false, "write each simulation step to stdout as a frame for the dashboard")
+
+// Writer emits frames. A nil *Writer discards everything, so solvers can
+// take one unconditionally and only pay for rendering when it's non-nil.
+type Writer struct {
+	w *bufio.Writer
+}
+
+// New returns a Writer on stdout if -frames was given, or nil otherwise.
+// Flags must have been parsed before calling this.
+func New() *Writer {
+	if !*enabled {
+		return nil
+	}
+	return NewWriter(os.Stdout)
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{bufio.NewWriter(w)}
+}
+
+// Emit writes a frame and flushes it, so the reader sees it straight away.
+// Writing blocks if the reader isn't keeping up, which is what lets the
+// dashboard pause a simulation.
+func (w *Writer) Emit(title string, lines []string) error {
+	if w == nil {
+		return nil
+	}
+
+	fmt.Fprintln(w.w, Header+title)
+	for _, line := range lines {
+		fmt.Fprintln(w.w, line)
+	}
+	fmt.Fprintln(w.w, Separator)
+	return w.w.Flush()
+}
+
+type Frame struct {
+	Title string
+	Lines []string
+}
+
+// Item is either a frame, or a plain line printed between frames
+type Item struct {
+	Frame *Frame
+	Line  string
+}
+
+type Reader struct {
+	scanner *bufio.Scanner
+}
+
+func NewReader(r io.Reader) *Reader {
+	scanner := bufio.NewScanner(r)
+	// frames of a whole sand cave can be a fair bit longer than the default
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	return &Reader{scanner}
+}
+
+// Next returns the next frame or line, or io.EOF once the stream is done.
+func (r *Reader) Next() (Item, error) {
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return Item{}, err
+		}
+		return Item{}, io.EOF
+	}
+
+	line := r.scanner.Text()
+	if !strings.HasPrefix(line, Header) {
+		return Item{Line: line}, nil
+	}
+
+	frame := &Frame{Title: strings.TrimPrefix(line, Header)}
+	for r.scanner.Scan() {
+		line := r.scanner.Text()
+		if line == Separator {
+			return Item{Frame: frame}, nil
+		}
+		frame.Lines = append(frame.Lines, line)
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return Item{}, err
+	}
+	return Item{}, fmt.Errorf("stream ended part way through frame %q", frame.Title)
+}
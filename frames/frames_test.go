@@ -0,0 +1,65 @@
+package frames
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	var b bytes.Buffer
+	w := NewWriter(&b)
+	b.WriteString("Part 1: 24\n")
+	if err := w.Emit("step 1", []string{"#..", ".#.", "..#"}); err != nil {
+		t.Fatalf("failed to emit: %v", err)
+	}
+	if err := w.Emit("empty", nil); err != nil {
+		t.Fatalf("failed to emit: %v", err)
+	}
+	b.WriteString("Part 2: 93\n")
+
+	// a nil writer discards frames
+	var discard *Writer
+	if err := discard.Emit("ignored", []string{"x"}); err != nil {
+		t.Errorf("nil writer failed: %v", err)
+	}
+
+	r := NewReader(&b)
+	expected := []Item{
+		{Line: "Part 1: 24"},
+		{Frame: &Frame{Title: "step 1", Lines: []string{"#..", ".#.", "..#"}}},
+		{Frame: &Frame{Title: "empty"}},
+		{Line: "Part 2: 93"},
+	}
+	for _, e := range expected {
+		item, err := r.Next()
+		if err != nil || !reflect.DeepEqual(item, e) {
+			t.Errorf("read %+v, %v, expected %+v", item, err, e)
+		}
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("expected EOF, got %v", err)
+	}
+}
+
+func TestMalformedFrame(t *testing.T) {
+	// the stream stops before the frame's separator
+	r := NewReader(strings.NewReader(Header + "cut off\nline 1\nline 2\n"))
+	if _, err := r.Next(); err == nil || err == io.EOF {
+		t.Errorf("expected an error for a frame with no end, got %v", err)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
+func TestEmitFailsWhenReaderGoes(t *testing.T) {
+	if err := NewWriter(failingWriter{}).Emit("title", []string{"x"}); err == nil {
+		t.Error("expected an error writing to a closed pipe")
+	}
+}
@@ -0,0 +1,224 @@
+// Package metrics counts how much work the search-heavy solvers do, as
+// opposed to just how long they take.
+//
+// Every metric is published through expvar, so running a day with -serve
+// exposes them at /debug/vars while it's solving. Running with -stats prints
+// a summary to stderr once it's done.
+package metrics
+
+import (
+	"encoding/json"
+	"expvar"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	stats = flag.Bool("stats", false, "print a summary of solver metrics once done")
+	serve = flag.String("serve", "", "address to serve solver metrics on via expvar, e.g. localhost:6060")
+)
+
+// metrics in the order they were registered, for printing the summary
+var (
+	registryLock sync.Mutex
+	registry     []namedVar
+)
+
+type namedVar struct {
+	name string
+	v    summariser
+}
+
+type summariser interface {
+	expvar.Var
+	summary() string
+}
+
+func register(name string, v summariser) {
+	expvar.Publish(name, v)
+
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	registry = append(registry, namedVar{name, v})
+}
+
+// Counter is a count which only goes up, safe to use from many goroutines
+type Counter struct {
+	value atomic.Int64
+}
+
+func NewCounter(name string) *Counter {
+	c := &Counter{}
+	register(name, c)
+	return c
+}
+
+func (c *Counter) Inc() {
+	c.value.Add(1)
+}
+
+func (c *Counter) Add(n int64) {
+	c.value.Add(n)
+}
+
+func (c *Counter) Value() int64 {
+	return c.value.Load()
+}
+
+func (c *Counter) String() string {
+	return fmt.Sprint(c.Value())
+}
+
+func (c *Counter) summary() string {
+	return c.String()
+}
+
+// Histogram counts observations into buckets. Each bucket counts the values
+// less than or equal to its bound (and greater than the previous bound),
+// with a final bucket for anything larger than the last bound.
+type Histogram struct {
+	lock   sync.Mutex
+	bounds []float64
+	counts []int64
+	count  int64
+	sum    float64
+	min    float64
+	max    float64
+}
+
+func NewHistogram(name string, bounds []float64) *Histogram {
+	sorted := make([]float64, len(bounds))
+	copy(sorted, bounds)
+	sort.Float64s(sorted)
+
+	h := &Histogram{
+		bounds: sorted,
+		counts: make([]int64, len(sorted)+1),
+		min:    math.Inf(1),
+		max:    math.Inf(-1),
+	}
+	register(name, h)
+	return h
+}
+
+// ExponentialBounds gives count bounds starting at start, each factor times
+// the previous one, which suits values that range over orders of magnitude
+func ExponentialBounds(start float64, factor float64, count int) []float64 {
+	bounds := make([]float64, count)
+	for i := range bounds {
+		bounds[i] = start
+		start *= factor
+	}
+	return bounds
+}
+
+func (h *Histogram) Observe(v float64) {
+	// the first bound >= v is the bucket it belongs in
+	i := sort.SearchFloat64s(h.bounds, v)
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.counts[i]++
+	h.count++
+	h.sum += v
+	h.min = math.Min(h.min, v)
+	h.max = math.Max(h.max, v)
+}
+
+type histogramJSON struct {
+	Count   int64     `json:"count"`
+	Sum     float64   `json:"sum"`
+	Min     float64   `json:"min"`
+	Max     float64   `json:"max"`
+	Bounds  []float64 `json:"bounds"`
+	Buckets []int64   `json:"buckets"`
+}
+
+func (h *Histogram) snapshot() histogramJSON {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	counts := make([]int64, len(h.counts))
+	copy(counts, h.counts)
+	snapshot := histogramJSON{h.count, h.sum, h.min, h.max, h.bounds, counts}
+
+	// infinities aren't valid JSON
+	if h.count == 0 {
+		snapshot.Min, snapshot.Max = 0, 0
+	}
+	return snapshot
+}
+
+func (h *Histogram) String() string {
+	out, err := json.Marshal(h.snapshot())
+	if err != nil {
+		return "null"
+	}
+	return string(out)
+}
+
+func (h *Histogram) summary() string {
+	s := h.snapshot()
+	if s.Count == 0 {
+		return "no observations"
+	}
+
+	lines := []string{fmt.Sprintf("count %d, mean %.2f, min %v, max %v", s.Count, s.Sum/float64(s.Count), s.Min, s.Max)}
+	for i, count := range s.Buckets {
+		if count == 0 {
+			continue
+		}
+		label := "+Inf"
+		if i < len(s.Bounds) {
+			label = fmt.Sprint(s.Bounds[i])
+		}
+		lines = append(lines, fmt.Sprintf("  <= %-10s %d", label, count))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Start serves the metrics if -serve was given. Flags must have been parsed
+// before calling this.
+func Start() error {
+	if *serve == "" {
+		return nil
+	}
+
+	// listen up front, so a bad address is reported rather than lost
+	listener, err := net.Listen("tcp", *serve)
+	if err != nil {
+		return fmt.Errorf("failed to serve metrics: %v", err)
+	}
+
+	// importing expvar registers /debug/vars on the default mux
+	go http.Serve(listener, nil)
+	fmt.Fprintf(os.Stderr, "serving metrics on http://%s/debug/vars\n", listener.Addr())
+	return nil
+}
+
+// Finish prints the summary to stderr if -stats was given
+func Finish() {
+	if *stats {
+		Summary(os.Stderr)
+	}
+}
+
+// Summary writes every registered metric in a human-readable form
+func Summary(w io.Writer) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	fmt.Fprintln(w, "Stats:")
+	for _, m := range registry {
+		fmt.Fprintf(w, "%s: %s\n", m.name, m.v.summary())
+	}
+}
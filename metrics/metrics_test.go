@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"encoding/json"
+	"expvar"
+	"reflect"
+	"testing"
+)
+
+func TestHistogramBuckets(t *testing.T) {
+	h := NewHistogram("test_histogram", []float64{10, 1, 100})
+	for _, v := range []float64{0, 1, 2, 10, 50, 1000} {
+		h.Observe(v)
+	}
+
+	s := h.snapshot()
+	if expected := []int64{2, 2, 1, 1}; !reflect.DeepEqual(s.Buckets, expected) {
+		t.Errorf("expected buckets %v, got %v", expected, s.Buckets)
+	}
+
+	if s.Count != 6 || s.Sum != 1063 || s.Min != 0 || s.Max != 1000 {
+		t.Errorf("unexpected totals %+v", s)
+	}
+}
+
+func TestPublishedAsJSON(t *testing.T) {
+	c := NewCounter("test_counter")
+	c.Add(41)
+	c.Inc()
+	NewHistogram("test_empty_histogram", ExponentialBounds(1, 2, 3))
+
+	if v := expvar.Get("test_counter").String(); v != "42" {
+		t.Errorf("expected counter to publish 42, got %s", v)
+	}
+
+	var decoded histogramJSON
+	if err := json.Unmarshal([]byte(expvar.Get("test_empty_histogram").String()), &decoded); err != nil {
+		t.Fatalf("empty histogram isn't valid JSON: %v", err)
+	}
+	if !reflect.DeepEqual(decoded.Bounds, []float64{1, 2, 4}) {
+		t.Errorf("unexpected bounds %v", decoded.Bounds)
+	}
+}
@@ -0,0 +1,199 @@
+// Package params lets each day expose its puzzle constants as named
+// parameters, so that the worked examples (which tend to use different
+// constants to the real puzzle) can be solved by the same binary.
+//
+// Parameters are ordinary flags on flag.CommandLine, so the defaults live
+// next to the solver that uses them. On top of that, Parse adds a -config
+// flag which reads values from a JSON or (flat) TOML file. Anything given
+// explicitly on the command line wins over the config file.
+package params
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Parse parses the command line, then applies any values from the -config
+// file which weren't explicitly set as flags.
+func Parse() error {
+	configPath := flag.String("config", "", "JSON or TOML file of parameter values")
+	flag.Parse()
+
+	if *configPath == "" {
+		return nil
+	}
+
+	values, err := ReadFile(*configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %v", err)
+	}
+
+	return Apply(flag.CommandLine, values)
+}
+
+// Apply sets each value on the flag set, skipping any flag that has already
+// been set so that the command line takes priority.
+func Apply(fs *flag.FlagSet, values map[string]string) error {
+	alreadySet := map[string]struct{}{}
+	fs.Visit(func(f *flag.Flag) {
+		alreadySet[f.Name] = struct{}{}
+	})
+
+	for name, value := range values {
+		if fs.Lookup(name) == nil {
+			return fmt.Errorf("unknown parameter %s", name)
+		}
+
+		if _, set := alreadySet[name]; set {
+			continue
+		}
+
+		if err := fs.Set(name, value); err != nil {
+			return fmt.Errorf("invalid value %q for parameter %s: %v", value, name, err)
+		}
+	}
+	return nil
+}
+
+// ReadFile reads a config file, picking the format from its extension.
+func ReadFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch filepath.Ext(path) {
+	case ".json":
+		return parseJSON(data)
+	case ".toml":
+		return parseTOML(data)
+	default:
+		return nil, fmt.Errorf("unknown config format for %s, expected .json or .toml", path)
+	}
+}
+
+func parseJSON(data []byte) (map[string]string, error) {
+	// UseNumber stops large integers like 1000000000000 coming back as
+	// floats, which would print as 1e+12 and fail to parse as an int flag
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	raw := map[string]any{}
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON: %v", err)
+	}
+
+	values := make(map[string]string, len(raw))
+	for name, value := range raw {
+		switch v := value.(type) {
+		case string:
+			values[name] = v
+		case json.Number:
+			values[name] = v.String()
+		case bool:
+			values[name] = strconv.FormatBool(v)
+		case []any:
+			// lists become comma-separated, which is what Ints expects
+			parts := make([]string, 0, len(v))
+			for _, item := range v {
+				parts = append(parts, fmt.Sprint(item))
+			}
+			values[name] = strings.Join(parts, ",")
+		default:
+			return nil, fmt.Errorf("unsupported value for %s: %v", name, value)
+		}
+	}
+	return values, nil
+}
+
+// We only need flat key = value pairs, so this is a small subset of TOML
+// rather than pulling in a full parser: no tables, no multi-line values.
+func parseTOML(data []byte) (map[string]string, error) {
+	values := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		name, value, found := strings.Cut(line, "=")
+		if !found {
+			return nil, fmt.Errorf("line %d is not of the form key = value", lineNo)
+		}
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+
+		if strings.HasPrefix(value, `"`) {
+			// the string can be followed by a comment, so only unquote up to
+			// its closing quote
+			quoted, err := strconv.QuotedPrefix(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d has an invalid string: %v", lineNo, err)
+			}
+			if rest := strings.TrimSpace(value[len(quoted):]); rest != "" && rest[0] != '#' {
+				return nil, fmt.Errorf("line %d has %q after its string", lineNo, rest)
+			}
+			value, err = strconv.Unquote(quoted)
+			if err != nil {
+				return nil, fmt.Errorf("line %d has an invalid string: %v", lineNo, err)
+			}
+		} else {
+			// strip trailing comments from unquoted values
+			value, _, _ = strings.Cut(value, "#")
+			value = strings.TrimSpace(value)
+		}
+
+		if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+			value = strings.ReplaceAll(strings.Trim(value, "[]"), " ", "")
+		}
+
+		// TOML allows underscores as digit separators, e.g. 4_000_000
+		if _, err := strconv.Atoi(strings.ReplaceAll(value, "_", "")); err == nil {
+			value = strings.ReplaceAll(value, "_", "")
+		}
+
+		values[name] = value
+	}
+
+	return values, scanner.Err()
+}
+
+// IntList is a comma-separated list of integers, usable as a flag value.
+type IntList []int
+
+func (l *IntList) String() string {
+	parts := make([]string, len(*l))
+	for i, v := range *l {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ",")
+}
+
+func (l *IntList) Set(s string) error {
+	parts := strings.Split(s, ",")
+	values := make(IntList, 0, len(parts))
+	for _, part := range parts {
+		v, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return fmt.Errorf("failed to parse %q as an int: %v", part, err)
+		}
+		values = append(values, v)
+	}
+	*l = values
+	return nil
+}
+
+// Ints defines a comma-separated integer list flag on flag.CommandLine.
+func Ints(name string, value []int, usage string) *IntList {
+	l := IntList(value)
+	flag.Var(&l, name, usage)
+	return &l
+}
@@ -0,0 +1,118 @@
+package params
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	values, err := parseTOML([]byte(`
+# the example's constants
+start = "AA" # a comment after a string
+name = "hash # inside"
+rows = 2_000_000
+steps = 30 # a comment after a number
+costs = [1, 2, 3]
+escaped = "a\"b"
+`))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+
+	expected := map[string]string{
+		"start":   "AA",
+		"name":    "hash # inside",
+		"rows":    "2000000",
+		"steps":   "30",
+		"costs":   "1,2,3",
+		"escaped": `a"b`,
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("parsed %v, expected %v", values, expected)
+	}
+
+	for _, bad := range []string{
+		"no equals sign",
+		`start = "unterminated`,
+		`start = "AA" junk`,
+	} {
+		if _, err := parseTOML([]byte(bad)); err == nil {
+			t.Errorf("expected an error parsing %q", bad)
+		}
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	values, err := parseJSON([]byte(`{"start": "AA", "big": 1000000000000, "debug": true, "costs": [1, 2, 3]}`))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+
+	expected := map[string]string{"start": "AA", "big": "1000000000000", "debug": "true", "costs": "1,2,3"}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("parsed %v, expected %v", values, expected)
+	}
+
+	for _, bad := range []string{`{"start": `, `{"nested": {"a": 1}}`} {
+		if _, err := parseJSON([]byte(bad)); err == nil {
+			t.Errorf("expected an error parsing %q", bad)
+		}
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+	for name, contents := range map[string]string{
+		"config.toml": "rows = 10\n",
+		"config.json": `{"rows": 10}`,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+
+		values, err := ReadFile(path)
+		if err != nil || values["rows"] != "10" {
+			t.Errorf("reading %s gave %v, %v", name, values, err)
+		}
+	}
+
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("rows: 10\n"), 0644); err != nil {
+		t.Fatalf("failed to write config.yaml: %v", err)
+	}
+	if _, err := ReadFile(path); err == nil {
+		t.Error("expected an error reading an unknown format")
+	}
+}
+
+func TestApplyPrefersFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	rows := fs.Int("rows", 1, "")
+	steps := fs.Int("steps", 1, "")
+	fs.Int("seed", 1, "")
+	costs := IntList{}
+	fs.Var(&costs, "costs", "")
+
+	if err := fs.Parse([]string{"-rows", "5"}); err != nil {
+		t.Fatalf("failed to parse flags: %v", err)
+	}
+	if err := Apply(fs, map[string]string{"rows": "10", "steps": "30", "costs": "1,2"}); err != nil {
+		t.Fatalf("failed to apply: %v", err)
+	}
+
+	// the explicit flag wins, and the config fills in the rest
+	if *rows != 5 || *steps != 30 || !reflect.DeepEqual(costs, IntList{1, 2}) {
+		t.Errorf("got rows %d, steps %d, costs %v", *rows, *steps, costs)
+	}
+
+	if err := Apply(fs, map[string]string{"unknown": "1"}); err == nil {
+		t.Error("expected an error for an unknown parameter")
+	}
+	if err := Apply(fs, map[string]string{"seed": "lots"}); err == nil {
+		t.Error("expected an error for an invalid value")
+	}
+}